@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"github.com/olekukonko/tablewriter"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create or update the Kubernetes resources described in a YAML file",
+	Long: `Apply every resource found in a YAML file with server-side apply,
+using "kuba" as the field manager. Objects that do not exist are created,
+objects that differ are updated and the rest are left alone, so the same
+manifest can be applied any number of times. Each object is reported as
+created, configured or unchanged.
+
+When another field manager owns a field the manifest sets, the apply fails
+with a conflict; use --force-conflicts to take the field over.
+
+With --app the applied resources are recorded in the inventory of that app,
+kept in --inventory-namespace ("default" unless given), so one app may span
+several namespaces. --prune deletes the recorded
+resources that left the manifests, after listing them and asking for
+confirmation; --yes skips it. To only preview the prune, run kuba create
+with --dry-run and the same --app first.
+
+Example:
+  kuba apply --fp=./TestYamls/testDeployment.yaml --ns=default
+  kuba apply --fp=./TestYamls -R --app=shop --prune`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		forceConflicts, _ := cmd.Flags().GetBool("force-conflicts")
+		inventory, err := inventoryFlags(cmd)
+		if err != nil {
+			return err
+		}
+		manifests, err := manifestFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		dynamicClient, err := kubernetesClient.GetDynamicClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		mapper, err := kubernetesClient.GetRESTMapper()
+		if err != nil {
+			return fmt.Errorf("discovering cluster resources: %w", err)
+		}
+		results, err := handlers.YamlResourceApplier(dynamicClient, mapper, namespace, manifests, forceConflicts)
+		if err != nil {
+			return fmt.Errorf("reading resources: %w", err)
+		}
+
+		failed := 0
+		var firstErr error
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Kind", "Name", "Namespace", "Result"})
+		for _, result := range results {
+			status := result.Action
+			if result.Err != nil {
+				status = result.Err.Error()
+				failed++
+				if firstErr == nil {
+					firstErr = result.Err
+				}
+			}
+			table.Append([]string{result.Kind, result.Name, result.Namespace, status})
+		}
+		table.Render()
+
+		var applyErr error
+		if failed > 0 {
+			applyErr = fmt.Errorf("%d of %d resources failed to apply: %w", failed, len(results), firstErr)
+		}
+		if inventory.app != "" {
+			current, complete := handlers.InventoryFromApply(results)
+			inventoryErr := updateInventory(dynamicClient, mapper, inventory, current, complete, handlers.DryRunNone)
+			return errors.Join(applyErr, inventoryErr)
+		}
+		return applyErr
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(applyCmd)
+	addManifestFlags(applyCmd)
+	addInventoryFlags(applyCmd)
+	applyCmd.PersistentFlags().Bool("force-conflicts", false, "Take over fields owned by other field managers instead of failing with a conflict")
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"golang.org/x/term"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// cpCmd represents the cp command
+var cpCmd = &cobra.Command{
+	Use:   "cp <source> <destination>",
+	Short: "Copy files and directories to and from containers",
+	Long: `Copy a file or directory between the local machine and a container. The
+side in the pod is written as <pod>:<path>. The copy is streamed as a tar
+archive over exec, so the container needs a tar binary; file modes are kept.
+
+A local destination that is a directory receives the copy inside it, and a
+destination in the pod ending in "/" is a directory to copy into. Progress
+is shown on a terminal as the bytes are copied; copies from a pod show the
+bytes copied without a percentage, since the size of the remote files is not
+known before the copy.
+
+Examples:
+  kuba cp test-pod:/var/dumps/heap.hprof ./heap.hprof --ns=default
+  kuba cp ./debug.yaml test-pod:/etc/app/ -c app
+  kuba cp test-pod:/var/log/app ./logs`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		container, _ := cmd.Flags().GetString("container")
+		if len(args) != 2 {
+			return usageErrorf("please provide a source and a destination (eg: kuba cp test-pod:/tmp/dump ./dump)")
+		}
+		sourcePod, sourcePath := splitPodPath(args[0])
+		destinationPod, destinationPath := splitPodPath(args[1])
+		if (sourcePod == "") == (destinationPod == "") {
+			return usageErrorf("exactly one of source and destination must be in a pod, written as <pod>:<path>")
+		}
+		podName := sourcePod + destinationPod
+
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		config, err := kubernetesClient.GetConfig()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		podDetailsList, err := handlers.PodDetailsRetrieve(client, namespace, podName)
+		if err != nil {
+			return fmt.Errorf("getting pod details: %w", err)
+		}
+		containers := podDetailsList[0].ContainerDetails
+		if container == "" && len(containers) > 0 {
+			container = containers[0].ContainerName
+		} else if !hasContainer(containers, container) {
+			return usageErrorf("pod %s has no container %q, it has %s", podName, container, containerNames(containers))
+		}
+
+		progress := copyProgressPrinter(term.IsTerminal(int(os.Stderr.Fd())))
+		var last handlers.CopyProgress
+		report := func(current handlers.CopyProgress) {
+			last = current
+			progress(current)
+		}
+		if sourcePod != "" {
+			err = handlers.CopyFromPod(cmd.Context(), config, namespace, podName, container, sourcePath, destinationPath, report)
+		} else {
+			err = handlers.CopyToPod(cmd.Context(), config, namespace, podName, container, sourcePath, destinationPath, report)
+		}
+		progress(handlers.CopyProgress{})
+		if err != nil {
+			return fmt.Errorf("copying %s to %s: %w", args[0], args[1], err)
+		}
+		fmt.Printf("Copied %d files (%s) from %s to %s\n", last.Files, humanBytes(last.Bytes), args[0], args[1])
+		return nil
+	},
+}
+
+// splitPodPath splits "<pod>:<path>"; local paths have no pod.
+func splitPodPath(arg string) (string, string) {
+	pod, path, found := strings.Cut(arg, ":")
+	if !found || pod == "" || strings.ContainsAny(pod, `/\`) {
+		return "", arg
+	}
+	return pod, path
+}
+
+// copyProgressPrinter returns a func that redraws a progress line on
+// stderr, or does nothing when stderr is not a terminal. A progress without
+// a file clears the line.
+func copyProgressPrinter(enabled bool) func(handlers.CopyProgress) {
+	return func(progress handlers.CopyProgress) {
+		if !enabled {
+			return
+		}
+		if progress.File == "" {
+			fmt.Fprint(os.Stderr, "\r\033[K")
+			return
+		}
+		copied := humanBytes(progress.Bytes)
+		if progress.TotalBytes > 0 {
+			copied = fmt.Sprintf("%s of %s (%d%%)", copied, humanBytes(progress.TotalBytes), progress.Bytes*100/progress.TotalBytes)
+		}
+		fmt.Fprintf(os.Stderr, "\r\033[KCopying %d files, %s: %s", progress.Files, copied, progress.File)
+	}
+}
+
+// humanBytes formats a size with a binary unit, such as "3.4 MiB".
+func humanBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	divisor, exponent := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		divisor *= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(divisor), "KMGTPE"[exponent])
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(cpCmd)
+	cpCmd.Flags().StringP("container", "c", "", "The container to copy to or from, the pod's first container by default")
+}
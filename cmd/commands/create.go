@@ -4,8 +4,15 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"github.com/olekukonko/tablewriter"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/dynamic"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -13,21 +20,149 @@ import (
 // createCmd represents the create command
 var createCmd = &cobra.Command{
 	Use:   "create",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("create called")
+	Short: "Create the Kubernetes resources described in a YAML file",
+	Long: `Create every resource found in YAML or JSON manifests. --fp takes files,
+directories (add -R to include subdirectories) or '-' for stdin, and can be
+repeated. Multi-document files separated by "---" are supported; objects from
+all files are ordered together: namespaces are created first, then
+configuration (secrets, config maps, services) and finally workloads.
+Any kind served by the cluster can be created, including custom resources.
+
+With --dry-run=client the objects are only checked against the kinds the
+cluster serves and printed as they would be sent; with --dry-run=server the
+API server validates and admits them without persisting anything.
+
+With --wait kuba watches every created resource until it is ready, as
+"kuba wait" does, and fails when one is not ready within --timeout.
+
+With --atomic kuba stops at the first resource that fails to create, or
+that fails the --wait, and deletes every resource it created in this run in
+reverse order, then prints a rollback report.
+
+With --app the created resources, whatever their namespaces, are recorded in
+the inventory of that app, a labelled config map in --inventory-namespace
+("default" unless given). Adding --prune deletes the resources recorded earlier that are no longer in the
+manifests, after listing them and asking for confirmation (--yes skips it);
+combine it with --dry-run to only preview what would be pruned.
+
+Example:
+  kuba create --fp=./TestYamls/testDeployment.yaml --ns=default
+  kuba create --fp=./TestYamls -R --wait --timeout=2m
+  cat deployment.yaml | kuba create --fp=-
+  kuba create --fp=./TestYamls/testDeployment.yaml --dry-run=server
+  kuba create --fp=./TestYamls --atomic --wait
+  kuba create --fp=./TestYamls -R --app=shop --prune --dry-run=client`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		manifests, err := manifestFlags(cmd)
+		if err != nil {
+			return err
+		}
+		dryRun, err := dryRunFlag(cmd)
+		if err != nil {
+			return err
+		}
+		wait, _ := cmd.Flags().GetBool("wait")
+		atomic, _ := cmd.Flags().GetBool("atomic")
+		inventory, err := inventoryFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		dynamicClient, err := kubernetesClient.GetDynamicClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		mapper, err := kubernetesClient.GetRESTMapper()
+		if err != nil {
+			return fmt.Errorf("discovering cluster resources: %w", err)
+		}
+		create := handlers.YamlResourceCreator
+		if atomic {
+			create = handlers.YamlResourceCreatorAtomic
+		}
+		results, err := create(dynamicClient, mapper, namespace, manifests, dryRun)
+		if err != nil {
+			return fmt.Errorf("reading resources: %w", err)
+		}
+		if dryRun == handlers.DryRunClient {
+			if err := printObjects(results); err != nil {
+				return err
+			}
+		}
+
+		failed := 0
+		var firstErr error
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Kind", "Name", "Namespace", "Result"})
+		for _, result := range results {
+			status := "created" + dryRunSuffix(dryRun)
+			if result.Err != nil {
+				status = result.Err.Error()
+				failed++
+				if firstErr == nil {
+					firstErr = result.Err
+				}
+			}
+			table.Append([]string{result.Kind, result.Name, result.Namespace, status})
+		}
+		table.Render()
+
+		var createErr error
+		if failed > 0 {
+			createErr = fmt.Errorf("%d of %d resources failed to create: %w", failed, len(results), firstErr)
+		} else if wait && dryRun == handlers.DryRunNone {
+			createErr = waitForCreated(cmd, results)
+		}
+		if createErr != nil && atomic && dryRun == handlers.DryRunNone {
+			return rollbackCreated(dynamicClient, mapper, results, createErr)
+		}
+		if inventory.app != "" {
+			current, complete := handlers.InventoryFromCreate(results)
+			inventoryErr := updateInventory(dynamicClient, mapper, inventory, current, complete, dryRun)
+			return errors.Join(createErr, inventoryErr)
+		}
+		return createErr
 	},
 }
 
+// rollbackCreated deletes what an atomic create made, prints the outcome
+// for every object and returns createErr annotated with the rollback.
+func rollbackCreated(dynamicClient dynamic.Interface, mapper meta.RESTMapper, results []handlers.CreateResult, createErr error) error {
+	fmt.Printf("Rolling back: %v\n", createErr)
+	rollback := handlers.RollbackCreated(dynamicClient, mapper, results)
+	if len(rollback) == 0 {
+		fmt.Println("Nothing was created, nothing to roll back")
+		return createErr
+	}
+
+	failed := 0
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Name", "Namespace", "Rollback"})
+	for _, result := range rollback {
+		status := "deleted"
+		if result.Err != nil {
+			status = result.Err.Error()
+			failed++
+		}
+		table.Append([]string{result.Kind, result.Name, result.Namespace, status})
+	}
+	table.Render()
+
+	if failed > 0 {
+		return fmt.Errorf("%w; rollback left %d of %d resources behind", createErr, failed, len(rollback))
+	}
+	return fmt.Errorf("%w; rolled back %d resources", createErr, len(rollback))
+}
+
 func init() {
 	cmd.RootCmd.AddCommand(createCmd)
-	createCmd.PersistentFlags().String("fp", "", "You need to provide the file path of your YAML file. (eg: --fp=./deployment.yaml)")
+	addManifestFlags(createCmd)
+	addDryRunFlag(createCmd)
+	createCmd.PersistentFlags().Bool("wait", false, "Wait until the created resources are ready (eg: deployments available, jobs complete)")
+	addTimeoutFlag(createCmd, "How long --wait waits before giving up, 0 waits forever (eg: --timeout=2m)")
+	addInventoryFlags(createCmd)
+	createCmd.PersistentFlags().Bool("atomic", false, "Stop at the first failure and delete everything created in this run, in reverse order")
 
 	// Here you will define your flags and configuration settings.
 
@@ -6,6 +6,10 @@ package commands
 import (
 	"fmt"
 	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"github.com/olekukonko/tablewriter"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -13,15 +17,127 @@ import (
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
 	Use:   "delete",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("delete called")
+	Short: "Delete Kubernetes resources by name, label selector or all of a kind",
+	Long: `Delete a single resource by name, or every resource of a kind that
+matches a label selector (or all of them). Bulk deletions print the matching
+objects before deleting them and report the result for each object.
+The kind can be any resource served by the cluster, by name or short name
+(deployment, deploy, pvc, ingress, custom resources, ...).
+
+With --dry-run=client kuba only checks that the objects exist; with
+--dry-run=server the API server validates the deletion without deleting.
+
+For cluster-scoped kinds, such as namespaces, --all and --selector match
+objects across the whole cluster, so they need --confirm-cluster-scoped. Bulk deletes of namespaces
+always skip the system namespaces: default, kube-system, kube-public and
+kube-node-lease.
+
+Examples:
+  kuba delete --k=deployment --rn=test-deployment --ns=default
+  kuba delete --k=deployment --selector=app=test --ns=default
+  kuba delete --k=service --all --ns=default
+  kuba delete --k=deployment --selector=app=test --dry-run=server`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		kind, _ := cmd.Flags().GetString("k")
+		name, _ := cmd.Flags().GetString("rn")
+		selector, _ := cmd.Flags().GetString("selector")
+		all, _ := cmd.Flags().GetBool("all")
+		confirmClusterScoped, _ := cmd.Flags().GetBool("confirm-cluster-scoped")
+
+		if kind == "" {
+			return usageErrorf("please provide the kind of the resource to delete (eg: --k=deployment, --k=pvc, --k=ingress)")
+		}
+		modes := 0
+		for _, set := range []bool{name != "", selector != "", all} {
+			if set {
+				modes++
+			}
+		}
+		if modes != 1 {
+			return usageErrorf("please provide exactly one of --rn, --selector or --all")
+		}
+		if namespace == "" {
+			namespace = "default"
+		}
+		dryRun, err := dryRunFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		dynamicClient, err := kubernetesClient.GetDynamicClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		mapper, err := kubernetesClient.GetRESTMapper()
+		if err != nil {
+			return fmt.Errorf("discovering cluster resources: %w", err)
+		}
+
+		clusterScoped, err := handlers.IsClusterScoped(mapper, kind)
+		if err != nil {
+			return fmt.Errorf("deleting resource: %w", err)
+		}
+		if clusterScoped && name == "" && !confirmClusterScoped {
+			return usageErrorf("--all and --selector match every %s in the cluster, not only in one namespace; add --confirm-cluster-scoped to delete them", kind)
+		}
+
+		if name != "" {
+			err = handlers.ResourceDelete(dynamicClient, mapper, kind, name, namespace, dryRun)
+			if err != nil {
+				return fmt.Errorf("deleting resource: %w", err)
+			}
+			if clusterScoped {
+				fmt.Printf("Resource deleted%s: kind=%s, name=%s\n", dryRunSuffix(dryRun), kind, name)
+			} else {
+				fmt.Printf("Resource deleted%s: kind=%s, name=%s, namespace=%s\n", dryRunSuffix(dryRun), kind, name, namespace)
+			}
+			return nil
+		}
+
+		resources, err := handlers.ResourceSelect(dynamicClient, mapper, kind, namespace, selector)
+		if err != nil {
+			return fmt.Errorf("listing resources: %w", err)
+		}
+		resources, skipped := handlers.WithoutSystemNamespaces(resources)
+		for _, resource := range skipped {
+			fmt.Printf("Skipping system namespace %s\n", resource.Name)
+		}
+		if len(resources) == 0 && clusterScoped {
+			fmt.Printf("No %s resources found\n", kind)
+			return nil
+		}
+		if len(resources) == 0 {
+			fmt.Printf("No %s resources found in namespace %s\n", kind, namespace)
+			return nil
+		}
+
+		fmt.Printf("Deleting %d %s resource(s):\n", len(resources), kind)
+		for _, resource := range resources {
+			fmt.Println("  -", resource.Name)
+		}
+
+		failed := 0
+		var firstErr error
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Kind", "Name", "Namespace", "Result"})
+		for _, result := range handlers.ResourceDeleteAll(dynamicClient, mapper, resources, dryRun) {
+			status := "deleted" + dryRunSuffix(dryRun)
+			if result.Err != nil {
+				status = result.Err.Error()
+				failed++
+				if firstErr == nil {
+					firstErr = result.Err
+				}
+			}
+			table.Append([]string{result.Kind, result.Name, result.Namespace, status})
+		}
+		table.Render()
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d resources failed to delete: %w", failed, len(resources), firstErr)
+		}
+		return nil
 	},
 }
 
@@ -29,6 +145,10 @@ func init() {
 	cmd.RootCmd.AddCommand(deleteCmd)
 	deleteCmd.PersistentFlags().String("k", "", "You need to provide the kind of the resource that you want to delete. (eg: --k=deployment)")
 	deleteCmd.PersistentFlags().String("rn", "", "You need to provide the name of the resource that you want to delete. (eg: --rn=deployment-name)")
+	deleteCmd.PersistentFlags().StringP("selector", "l", "", "Delete every resource of the kind matching this label selector. (eg: --selector=app=test)")
+	deleteCmd.PersistentFlags().Bool("all", false, "Delete every resource of the kind in the namespace. (eg: --all)")
+	deleteCmd.PersistentFlags().Bool("confirm-cluster-scoped", false, "Confirm --all or --selector for a cluster-scoped kind, which match objects across the whole cluster (eg: --k=clusterrole --all --confirm-cluster-scoped)")
+	addDryRunFlag(deleteCmd)
 
 	// Here you will define your flags and configuration settings.
 
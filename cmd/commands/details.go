@@ -5,185 +5,223 @@ import (
 	"github.com/kanha-gupta/kuba/cmd"
 	"github.com/kanha-gupta/kuba/handlers"
 	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"github.com/kanha-gupta/kuba/printers"
 	"github.com/spf13/cobra"
-	"log"
+	"io"
 )
 
 var DetailsCommand = &cobra.Command{
 	Use:   "details",
 	Short: "show kubernetes resources details",
-	Run: func(cmd *cobra.Command, args []string) {
-		log.Print("please provide resource type to get details")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageErrorf("please provide the resource type to get details of (eg: kuba details pod --p=pod-name)")
 	},
 }
 
 var podCommand = &cobra.Command{
 	Use:   "pod",
 	Short: "Show details of a Kubernetes pod",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		namespace, _ := cmd.Flags().GetString("ns")
 		podName, _ := cmd.Flags().GetString("p")
+		if podName == "" {
+			return usageErrorf("please provide the name of the pod (eg: --p=pod-name)")
+		}
 
 		client, err := kubernetesClient.GetClient()
 		if err != nil {
-			log.Printf("error getting kubernetes client: %v", err)
+			return fmt.Errorf("getting kubernetes client: %w", err)
 		}
 		podDetailsList, err := handlers.PodDetailsRetrieve(client, namespace, podName)
 		if err != nil {
-			log.Printf("error getting pod details: %v", err)
-		} else {
-			for _, pod := range podDetailsList {
-				fmt.Println("Name:", pod.Name)
-				fmt.Println("Namespace:", pod.Namespace)
-				fmt.Println("Creation Time:", pod.CreationTime)
-				fmt.Println("Phase:", pod.Phase)
-				fmt.Println("IP:", pod.IP)
-
-				fmt.Println("Conditions:")
-				for _, condition := range pod.Conditions {
-					fmt.Println("\tType:", condition.Type)
-					fmt.Println("\tStatus:", condition.Status)
-					fmt.Println("\tLast Transition Time:", condition.LastTransitionTime)
-					fmt.Println("\tReason:", condition.Reason)
-					fmt.Println("\tMessage:", condition.Message)
-				}
-
-				fmt.Println("Container Details:")
-				for _, container := range pod.ContainerDetails {
-					fmt.Println("\tContainer Name:", container.ContainerName)
-					fmt.Println("\tPorts:")
-					for _, port := range container.Ports {
-						fmt.Println("\t\tPort Name:", port.PortName)
-						fmt.Println("\t\tProtocol:", port.Protocol)
-						fmt.Println("\t\tContainer Port:", port.ContainerPort)
-						fmt.Println("\t\tHost Port:", port.HostPort)
-					}
-				}
-
-				fmt.Println("-----------------------------------")
-			}
-
+			return fmt.Errorf("getting pod details: %w", err)
 		}
+		pod := podDetailsList[0]
+		return printOutput(cmd, printers.Output{
+			Data:     pod,
+			Names:    []string{"pod/" + pod.Name},
+			Raw:      rawObject("pod", namespace, pod.Name),
+			Describe: func(w io.Writer) { describePod(w, pod) },
+		})
 	},
 }
 
+func describePod(w io.Writer, pod handlers.PodDetails) {
+	fmt.Fprintln(w, "Name:", pod.Name)
+	fmt.Fprintln(w, "Namespace:", pod.Namespace)
+	fmt.Fprintln(w, "Creation Time:", pod.CreationTime)
+	fmt.Fprintln(w, "Phase:", pod.Phase)
+	fmt.Fprintln(w, "IP:", pod.IP)
+
+	fmt.Fprintln(w, "Conditions:")
+	for _, condition := range pod.Conditions {
+		fmt.Fprintln(w, "\tType:", condition.Type)
+		fmt.Fprintln(w, "\tStatus:", condition.Status)
+		fmt.Fprintln(w, "\tLast Transition Time:", condition.LastTransitionTime)
+		fmt.Fprintln(w, "\tReason:", condition.Reason)
+		fmt.Fprintln(w, "\tMessage:", condition.Message)
+	}
+
+	fmt.Fprintln(w, "Container Details:")
+	for _, container := range pod.ContainerDetails {
+		fmt.Fprintln(w, "\tContainer Name:", container.ContainerName)
+		fmt.Fprintln(w, "\tPorts:")
+		for _, port := range container.Ports {
+			fmt.Fprintln(w, "\t\tPort Name:", port.PortName)
+			fmt.Fprintln(w, "\t\tProtocol:", port.Protocol)
+			fmt.Fprintln(w, "\t\tContainer Port:", port.ContainerPort)
+			fmt.Fprintln(w, "\t\tHost Port:", port.HostPort)
+		}
+	}
+
+	fmt.Fprintln(w, "-----------------------------------")
+}
+
 var deploymentCommand = &cobra.Command{
 	Use:   "deployment",
 	Short: "Show details of a Kubernetes deployment",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		namespace, _ := cmd.Flags().GetString("ns")
 		deploymentName, _ := cmd.Flags().GetString("d")
+		if deploymentName == "" {
+			return usageErrorf("please provide the name of the deployment (eg: --d=deployment-name)")
+		}
 
 		client, err := kubernetesClient.GetClient()
 		if err != nil {
-			log.Printf("error getting kubernetes client: %v", err)
+			return fmt.Errorf("getting kubernetes client: %w", err)
 		}
 		deploymentDetailsList, err := handlers.GetDeploymentDetails(client, namespace, deploymentName)
 		if err != nil {
-			log.Printf("error getting deployment details: %v", err)
-		} else {
-			for _, deployment := range deploymentDetailsList {
-				fmt.Println("Name:", deployment.Name)
-				fmt.Println("Namespace:", deployment.Namespace)
-				fmt.Println("Creation Time:", deployment.CreationTime)
-				fmt.Println("Replicas:", deployment.Replicas)
-				fmt.Println("Available Replicas:", deployment.AvailableReplicas)
-				fmt.Println("Ready Replicas:", deployment.ReadyReplicas)
-				fmt.Println("Updated Replicas:", deployment.UpdatedReplicas)
-				fmt.Println("Strategy:", deployment.Strategy)
-				fmt.Println("Selector:", deployment.Selector)
-
-				fmt.Println("Containers:")
-				for _, container := range deployment.Containers {
-					fmt.Println("\tContainer Name:", container.ContainerName)
-					fmt.Println("\tPorts:")
-					for _, port := range container.Ports {
-						fmt.Println("\t\tPort Name:", port.PortName)
-						fmt.Println("\t\tProtocol:", port.Protocol)
-						fmt.Println("\t\tContainer Port:", port.ContainerPort)
-					}
-				}
-
-				fmt.Println("-----------------------------------")
-			}
-
+			return fmt.Errorf("getting deployment details: %w", err)
 		}
+		deployment := deploymentDetailsList[0]
+		return printOutput(cmd, printers.Output{
+			Data:     deployment,
+			Names:    []string{"deployment/" + deployment.Name},
+			Raw:      rawObject("deployment", namespace, deployment.Name),
+			Describe: func(w io.Writer) { describeDeployment(w, deployment) },
+		})
 	},
 }
+
+func describeDeployment(w io.Writer, deployment handlers.DeploymentDetails) {
+	fmt.Fprintln(w, "Name:", deployment.Name)
+	fmt.Fprintln(w, "Namespace:", deployment.Namespace)
+	fmt.Fprintln(w, "Creation Time:", deployment.CreationTime)
+	fmt.Fprintln(w, "Replicas:", deployment.Replicas)
+	fmt.Fprintln(w, "Available Replicas:", deployment.AvailableReplicas)
+	fmt.Fprintln(w, "Ready Replicas:", deployment.ReadyReplicas)
+	fmt.Fprintln(w, "Updated Replicas:", deployment.UpdatedReplicas)
+	fmt.Fprintln(w, "Strategy:", deployment.Strategy)
+	fmt.Fprintln(w, "Selector:", deployment.Selector)
+
+	fmt.Fprintln(w, "Containers:")
+	for _, container := range deployment.Containers {
+		fmt.Fprintln(w, "\tContainer Name:", container.ContainerName)
+		fmt.Fprintln(w, "\tPorts:")
+		for _, port := range container.Ports {
+			fmt.Fprintln(w, "\t\tPort Name:", port.PortName)
+			fmt.Fprintln(w, "\t\tProtocol:", port.Protocol)
+			fmt.Fprintln(w, "\t\tContainer Port:", port.ContainerPort)
+		}
+	}
+
+	fmt.Fprintln(w, "-----------------------------------")
+}
+
 var namespaceCommand = &cobra.Command{
 	Use:   "namespace",
 	Short: "Show details of a namespace",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		namespace, _ := cmd.Flags().GetString("ns")
 
 		client, err := kubernetesClient.GetClient()
 		if err != nil {
-			log.Printf("error getting kubernetes client: %v", err)
-			return
+			return fmt.Errorf("getting kubernetes client: %w", err)
 		}
 
 		nsDetailsList, err := handlers.NameSpaceDetailsRetrieve(client, namespace)
 		if err != nil {
-			log.Printf("error getting namespace details: %v", err)
-			return
+			return fmt.Errorf("getting namespace details: %w", err)
 		}
 
-		for _, ns := range nsDetailsList {
-			fmt.Println("Name:", ns.Name)
-			fmt.Println("Creation Time:", ns.CreationTime)
-			fmt.Println("Status:", ns.Status)
-			fmt.Println("Labels:", ns.Labels)
-			fmt.Println("Annotations:", ns.Annotations)
-			fmt.Println("Resource Quota:", ns.ResourceQuota)
-			fmt.Println("-----------------------------------")
-		}
+		ns := nsDetailsList[0]
+		return printOutput(cmd, printers.Output{
+			Data:     ns,
+			Names:    []string{"namespace/" + ns.Name},
+			Raw:      rawObject("namespace", "", ns.Name),
+			Describe: func(w io.Writer) { describeNamespace(w, ns) },
+		})
 	},
 }
+
+func describeNamespace(w io.Writer, ns handlers.NamespaceDetails) {
+	fmt.Fprintln(w, "Name:", ns.Name)
+	fmt.Fprintln(w, "Creation Time:", ns.CreationTime)
+	fmt.Fprintln(w, "Status:", ns.Status)
+	fmt.Fprintln(w, "Labels:", ns.Labels)
+	fmt.Fprintln(w, "Annotations:", ns.Annotations)
+	fmt.Fprintln(w, "Resource Quota:", ns.ResourceQuota)
+	fmt.Fprintln(w, "-----------------------------------")
+}
+
 var serviceCommand = &cobra.Command{
 	Use:   "service",
 	Short: "Get details of a Kubernetes service",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		namespace, _ := cmd.Flags().GetString("ns")
 		serviceName, _ := cmd.Flags().GetString("s")
+		if serviceName == "" {
+			return usageErrorf("please provide the name of the service (eg: --s=service-name)")
+		}
 
 		client, err := kubernetesClient.GetClient()
 		if err != nil {
-			log.Printf("error getting kubernetes client: %v", err)
+			return fmt.Errorf("getting kubernetes client: %w", err)
 		}
 		serviceDetailsList, err := handlers.ServiceDetailsRetrieve(client, namespace, serviceName)
 		if err != nil {
-			log.Printf("error getting service details: %v", err)
-		} else {
-			for _, service := range serviceDetailsList {
-				fmt.Println("Service Name:", service.Name)
-				fmt.Println("Namespace:", service.Namespace)
-				fmt.Println("Creation Time:", service.CreationTime)
-				fmt.Println("Labels:", service.Labels)
-				fmt.Println("Type:", service.Type)
-				fmt.Println("Cluster IP:", service.ClusterIP)
-				fmt.Println("External IPs:", service.ExternalIPs)
-				fmt.Println("LoadBalancer IP:", service.LoadBalancerIP)
-
-				fmt.Println("Ports:")
-				for _, port := range service.Ports {
-					fmt.Println("  - Name:", port.Name)
-					fmt.Println("    Protocol:", port.Protocol)
-					fmt.Println("    Port:", port.Port)
-					fmt.Println("    Target Port:", port.TargetPort)
-					fmt.Println("    Node Port:", port.NodePort)
-				}
-
-				fmt.Println("Selector:", service.Selector)
-				fmt.Println("Session Affinity:", service.SessionAffinity)
-
-				fmt.Println("---------------------------")
-			}
+			return fmt.Errorf("getting service details: %w", err)
 		}
+		service := serviceDetailsList[0]
+		return printOutput(cmd, printers.Output{
+			Data:     service,
+			Names:    []string{"service/" + service.Name},
+			Raw:      rawObject("service", namespace, service.Name),
+			Describe: func(w io.Writer) { describeService(w, service) },
+		})
 	},
 }
 
+func describeService(w io.Writer, service handlers.ServiceDetails) {
+	fmt.Fprintln(w, "Service Name:", service.Name)
+	fmt.Fprintln(w, "Namespace:", service.Namespace)
+	fmt.Fprintln(w, "Creation Time:", service.CreationTime)
+	fmt.Fprintln(w, "Labels:", service.Labels)
+	fmt.Fprintln(w, "Type:", service.Type)
+	fmt.Fprintln(w, "Cluster IP:", service.ClusterIP)
+	fmt.Fprintln(w, "External IPs:", service.ExternalIPs)
+	fmt.Fprintln(w, "LoadBalancer IP:", service.LoadBalancerIP)
+
+	fmt.Fprintln(w, "Ports:")
+	for _, port := range service.Ports {
+		fmt.Fprintln(w, "  - Name:", port.Name)
+		fmt.Fprintln(w, "    Protocol:", port.Protocol)
+		fmt.Fprintln(w, "    Port:", port.Port)
+		fmt.Fprintln(w, "    Target Port:", port.TargetPort)
+		fmt.Fprintln(w, "    Node Port:", port.NodePort)
+	}
+
+	fmt.Fprintln(w, "Selector:", service.Selector)
+	fmt.Fprintln(w, "Session Affinity:", service.SessionAffinity)
+
+	fmt.Fprintln(w, "---------------------------")
+}
+
 func init() {
 	cmd.RootCmd.AddCommand(DetailsCommand)
+	addOutputFlag(DetailsCommand)
 	DetailsCommand.AddCommand(podCommand)
 	podCommand.PersistentFlags().String("p", "", "You need to provide the name of pod in order to get details of that perticular pod (eg: --p=pod-name)")
 	DetailsCommand.AddCommand(deploymentCommand)
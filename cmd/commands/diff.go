@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show what applying a YAML file would change in the cluster",
+	Long: `Compare every resource found in a YAML file with the live cluster and
+print a unified YAML diff per object. The new state comes from a server-side
+dry-run apply, so defaults filled in by the cluster do not show up as changes
+and nothing is modified. Managed fields and status are left out of the diff.
+
+Like kubectl diff, the exit code is 0 when nothing would change, 1 when there
+are differences and above 1 when diffing failed; failures that other commands
+report with 1 exit with 2.
+
+Example:
+  kuba diff --fp=./TestYamls/testDeployment.yaml --ns=default`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return diffExitError(runDiff(cmd))
+	},
+}
+
+// runDiff prints the diff of every manifest object and returns
+// errDifferences when any of them would change.
+func runDiff(cmd *cobra.Command) error {
+	namespace, _ := cmd.Flags().GetString("ns")
+	manifests, err := manifestFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	dynamicClient, err := kubernetesClient.GetDynamicClient()
+	if err != nil {
+		return fmt.Errorf("getting kubernetes client: %w", err)
+	}
+	mapper, err := kubernetesClient.GetRESTMapper()
+	if err != nil {
+		return fmt.Errorf("discovering cluster resources: %w", err)
+	}
+	results, err := handlers.YamlResourceDiffer(dynamicClient, mapper, namespace, manifests)
+	if err != nil {
+		return fmt.Errorf("reading resources: %w", err)
+	}
+
+	colored := term.IsTerminal(int(os.Stdout.Fd()))
+	failed, changed := 0, 0
+	var firstErr error
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "error diffing %s %s: %v\n", result.Kind, result.Name, result.Err)
+			failed++
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+			continue
+		}
+		if result.Diff != "" {
+			printDiff(os.Stdout, result.Diff, colored)
+			changed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d resources failed to diff: %w", failed, len(results), firstErr)
+	}
+	if changed > 0 {
+		return errDifferences
+	}
+	return nil
+}
+
+// errDifferences makes diff exit with 1 when applying would change objects.
+var errDifferences = &cmd.ExitError{Code: 1}
+
+// diffExitError keeps the exit code 1 for differences, so errors that would
+// exit with 1 exit with 2 instead.
+func diffExitError(err error) error {
+	if err == nil || err == errDifferences {
+		return err
+	}
+	if code := cmd.ExitCode(err); code == cmd.ExitGeneralError {
+		return &cmd.ExitError{Code: 2, Err: err}
+	}
+	return err
+}
+
+const (
+	colorReset   = "\033[0m"
+	colorBold    = "\033[1m"
+	colorRed     = "\033[31m"
+	colorGreen   = "\033[32m"
+	colorYellow  = "\033[33m"
+	colorBlue    = "\033[34m"
+	colorMagenta = "\033[35m"
+	colorCyan    = "\033[36m"
+)
+
+// printDiff writes a unified diff, coloring removed lines red, added lines
+// green and hunk headers cyan when colored is set.
+func printDiff(w io.Writer, diff string, colored bool) {
+	if !colored {
+		fmt.Fprint(w, diff)
+		return
+	}
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color = colorBold
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		}
+		if color == "" {
+			fmt.Fprint(w, line)
+			continue
+		}
+		fmt.Fprint(w, color+strings.TrimSuffix(line, "\n")+colorReset+"\n")
+	}
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(diffCmd)
+	addManifestFlags(diffCmd)
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+func addDryRunFlag(command *cobra.Command) {
+	command.PersistentFlags().String("dry-run", "none", "Preview the change instead of making it, one of: none|client|server (eg: --dry-run=server)")
+}
+
+func dryRunFlag(cmd *cobra.Command) (handlers.DryRunStrategy, error) {
+	value, _ := cmd.Flags().GetString("dry-run")
+	dryRun, err := handlers.ParseDryRun(value)
+	if err != nil {
+		return handlers.DryRunNone, usageErrorf("%v", err)
+	}
+	return dryRun, nil
+}
+
+// dryRunSuffix marks results that were only previewed.
+func dryRunSuffix(dryRun handlers.DryRunStrategy) string {
+	if dryRun == handlers.DryRunNone {
+		return ""
+	}
+	return fmt.Sprintf(" (%s dry run)", dryRun)
+}
+
+// printObjects prints the objects of a client dry run as the YAML that
+// would be sent to the cluster.
+func printObjects(results []handlers.CreateResult) error {
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		content, err := yaml.Marshal(result.Object.Object)
+		if err != nil {
+			return fmt.Errorf("printing %s %s: %w", result.Kind, result.Name, err)
+		}
+		fmt.Printf("---\n%s", content)
+	}
+	return nil
+}
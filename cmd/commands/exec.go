@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"github.com/olekukonko/tablewriter"
+	"golang.org/x/term"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+// execCmd represents the exec command
+var execCmd = &cobra.Command{
+	Use:   "exec (-p <pod> | --selector=<selector>) [-c <container>] -- <command> [args...]",
+	Short: "Run a command in a container of a pod",
+	Long: `Run a command in a container of a pod. -i passes stdin to the command
+and -t allocates a terminal, so "-it" opens an interactive shell. Without -c
+the pod's first container is used. kuba exits with the exit status of the
+remote command.
+
+With --selector the command runs, without stdin or terminal, in every
+running pod matching the selector; the output and exit status of each pod
+are printed in a table.
+
+Examples:
+  kuba exec -p test-pod -- ls /
+  kuba exec -it -p test-pod -c app -- sh
+  kuba exec --selector=app=web -- cat /etc/hostname`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		podName, _ := cmd.Flags().GetString("pod")
+		selector, _ := cmd.Flags().GetString("selector")
+		container, _ := cmd.Flags().GetString("container")
+		stdin, _ := cmd.Flags().GetBool("stdin")
+		tty, _ := cmd.Flags().GetBool("tty")
+
+		if len(args) == 0 || cmd.ArgsLenAtDash() != 0 {
+			return usageErrorf("please provide the command after -- (eg: kuba exec -p test-pod -- ls /)")
+		}
+		if (podName == "") == (selector == "") {
+			return usageErrorf("please provide either a pod with -p or --selector")
+		}
+		if selector != "" && (stdin || tty) {
+			return usageErrorf("-i and -t cannot be used with --selector")
+		}
+
+		config, err := kubernetesClient.GetConfig()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		if selector != "" {
+			results, err := handlers.ExecInPods(cmd.Context(), config, client, namespace, selector, container, args)
+			if err != nil {
+				return fmt.Errorf("running command: %w", err)
+			}
+			return printExecResults(results)
+		}
+
+		podDetailsList, err := handlers.PodDetailsRetrieve(client, namespace, podName)
+		if err != nil {
+			return fmt.Errorf("getting pod details: %w", err)
+		}
+		containers := podDetailsList[0].ContainerDetails
+		if container == "" && len(containers) > 0 {
+			container = containers[0].ContainerName
+			if len(containers) > 1 {
+				fmt.Fprintf(os.Stderr, "Defaulted container %q out of: %s\n", container, containerNames(containers))
+			}
+		} else if !hasContainer(containers, container) {
+			return usageErrorf("pod %s has no container %q, it has %s", podName, container, containerNames(containers))
+		}
+
+		options := handlers.ExecOptions{
+			Container: container,
+			Command:   args,
+			Stdout:    os.Stdout,
+			Stderr:    os.Stderr,
+			TTY:       tty,
+		}
+		if stdin {
+			options.Stdin = os.Stdin
+		}
+		if tty && stdin && term.IsTerminal(int(os.Stdin.Fd())) {
+			state, err := term.MakeRaw(int(os.Stdin.Fd()))
+			if err != nil {
+				return fmt.Errorf("setting up terminal: %w", err)
+			}
+			defer term.Restore(int(os.Stdin.Fd()), state)
+			sizes := newTerminalSize(int(os.Stdout.Fd()))
+			defer sizes.stop()
+			options.TerminalSizeQueue = sizes
+		}
+
+		err = handlers.ExecInPod(cmd.Context(), config, namespace, podName, options)
+		var exitErr exec.ExitError
+		if errors.As(err, &exitErr) {
+			return remoteExitError(exitErr.ExitStatus())
+		}
+		if err != nil {
+			return fmt.Errorf("running command in pod %s: %w", podName, err)
+		}
+		return nil
+	},
+}
+
+// remoteExitError makes kuba exit with the status of the remote command.
+func remoteExitError(code int) error {
+	return &cmd.ExitError{Code: code}
+}
+
+// printExecResults prints the output and exit status of every pod and
+// returns an error when the command failed in some of them.
+func printExecResults(results []handlers.ExecResult) error {
+	failed := 0
+	var firstErr error
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Pod", "Exit Code", "Output"})
+	table.SetAutoWrapText(false)
+	for _, result := range results {
+		output := strings.TrimRight(result.Output, "\n")
+		exitCode := strconv.Itoa(result.ExitCode)
+		if result.Err != nil {
+			output, exitCode = result.Err.Error(), "-"
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+		}
+		if result.Err != nil || result.ExitCode != 0 {
+			failed++
+		}
+		table.Append([]string{result.Pod, exitCode, output})
+	}
+	table.Render()
+
+	switch {
+	case firstErr != nil:
+		return fmt.Errorf("%d of %d pods failed to run the command: %w", failed, len(results), firstErr)
+	case failed > 0:
+		return fmt.Errorf("%d of %d pods failed to run the command", failed, len(results))
+	}
+	return nil
+}
+
+// terminalSize reports the size of the local terminal when the remote
+// terminal is allocated and again every time the local terminal is resized,
+// until stop is called.
+type terminalSize struct {
+	fd      int
+	resized chan os.Signal
+	done    chan struct{}
+	last    *remotecommand.TerminalSize
+}
+
+func newTerminalSize(fd int) *terminalSize {
+	t := &terminalSize{fd: fd, resized: make(chan os.Signal, 1), done: make(chan struct{})}
+	notifyResize(t.resized)
+	return t
+}
+
+func (t *terminalSize) Next() *remotecommand.TerminalSize {
+	for {
+		if t.last != nil {
+			select {
+			case <-t.resized:
+			case <-t.done:
+				return nil
+			}
+		}
+		width, height, err := term.GetSize(t.fd)
+		if err != nil {
+			return nil
+		}
+		size := &remotecommand.TerminalSize{Width: uint16(width), Height: uint16(height)}
+		if t.last == nil || *size != *t.last {
+			t.last = size
+			return size
+		}
+	}
+}
+
+func (t *terminalSize) stop() {
+	signal.Stop(t.resized)
+	close(t.done)
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(execCmd)
+	execCmd.Flags().StringP("pod", "p", "", "The pod to run the command in")
+	execCmd.Flags().StringP("selector", "l", "", "Run the command in every running pod matching this label selector (eg: -l app=web)")
+	execCmd.Flags().StringP("container", "c", "", "The container to run the command in, the pod's first container by default")
+	execCmd.Flags().BoolP("stdin", "i", false, "Pass stdin to the command")
+	execCmd.Flags().BoolP("tty", "t", false, "Allocate a terminal for the command")
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/dynamic"
+	"os"
+	"strings"
+)
+
+// inventoryOptions are the --app, --inventory-namespace, --prune and --yes
+// flags.
+type inventoryOptions struct {
+	app string
+	// namespace keeps the inventory, whatever the namespaces of the objects
+	// it records.
+	namespace string
+	prune     bool
+	// yes prunes without asking for confirmation.
+	yes bool
+}
+
+func addInventoryFlags(command *cobra.Command) {
+	command.PersistentFlags().String("app", "", "Record the resources in the inventory of this app, a labelled config map in --inventory-namespace (eg: --app=shop)")
+	command.PersistentFlags().String("inventory-namespace", corev1.NamespaceDefault, "The namespace of the --app inventory, independent of the namespaces of the resources (eg: --inventory-namespace=shop)")
+	command.PersistentFlags().Bool("prune", false, "Delete resources recorded in the --app inventory that are no longer in the manifests, after listing them and asking for confirmation")
+	command.PersistentFlags().Bool("yes", false, "Prune without asking for confirmation, as needed when stdin is not a terminal")
+}
+
+func inventoryFlags(cmd *cobra.Command) (inventoryOptions, error) {
+	var options inventoryOptions
+	options.app, _ = cmd.Flags().GetString("app")
+	options.namespace, _ = cmd.Flags().GetString("inventory-namespace")
+	options.prune, _ = cmd.Flags().GetBool("prune")
+	options.yes, _ = cmd.Flags().GetBool("yes")
+	if options.prune && options.app == "" {
+		return inventoryOptions{}, usageErrorf("--prune needs the app whose inventory to prune (eg: --app=shop --prune)")
+	}
+	if options.namespace == "" {
+		return inventoryOptions{}, usageErrorf("please provide the namespace of the inventory (eg: --inventory-namespace=default)")
+	}
+	return options, nil
+}
+
+// updateInventory records the current objects in the inventory of app and,
+// with prune, deletes the previously recorded objects that are gone from
+// the manifests. Pruning is skipped when the run was incomplete, since the
+// objects that failed would otherwise be pruned.
+func updateInventory(dynamicClient dynamic.Interface, mapper meta.RESTMapper, options inventoryOptions, current []handlers.InventoryObject, complete bool, dryRun handlers.DryRunStrategy) error {
+	app := options.app
+	previous, err := handlers.ReadInventory(dynamicClient, options.namespace, app)
+	if err != nil {
+		return fmt.Errorf("reading inventory of %s: %w", app, err)
+	}
+
+	record := handlers.MergeInventory(previous, current)
+	var pruneErr error
+	switch candidates := handlers.PruneCandidates(previous, current); {
+	case !options.prune:
+	case !complete:
+		fmt.Println("Skipping prune: some resources failed, so the manifest set is incomplete")
+	case len(candidates) == 0:
+		fmt.Printf("Nothing to prune for app %s\n", app)
+		record = current
+	default:
+		record, pruneErr = pruneInventory(dynamicClient, mapper, options, current, candidates, dryRun)
+	}
+
+	if err := handlers.WriteInventory(dynamicClient, options.namespace, app, record, dryRun); err != nil {
+		return fmt.Errorf("writing inventory of %s: %w", app, err)
+	}
+	fmt.Printf("Inventory %s/%s records %d resource(s)%s\n", options.namespace, handlers.InventoryName(app), len(record), dryRunSuffix(dryRun))
+	return pruneErr
+}
+
+// pruneInventory lists candidates and, once confirmed, deletes them. It
+// returns what the inventory should record: the current objects and the
+// ones left behind.
+func pruneInventory(dynamicClient dynamic.Interface, mapper meta.RESTMapper, options inventoryOptions, current []handlers.InventoryObject, candidates []handlers.InventoryObject, dryRun handlers.DryRunStrategy) ([]handlers.InventoryObject, error) {
+	fmt.Printf("Pruning %d resource(s) of app %s that are no longer in the manifests:\n", len(candidates), options.app)
+	for _, candidate := range candidates {
+		fmt.Println("  -", candidate)
+	}
+	if dryRun == handlers.DryRunNone && !options.yes {
+		confirmed, err := confirmPrune(len(candidates))
+		if err != nil || !confirmed {
+			return handlers.MergeInventory(candidates, current), err
+		}
+	}
+
+	record := current
+	failed := 0
+	var firstErr error
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Name", "Namespace", "Prune"})
+	for _, result := range handlers.PruneObjects(dynamicClient, mapper, candidates, dryRun) {
+		status := "pruned" + dryRunSuffix(dryRun)
+		if result.Err != nil {
+			status = result.Err.Error()
+			failed++
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+			for _, candidate := range candidates {
+				if candidate.Kind == result.Kind && candidate.Namespace == result.Namespace && candidate.Name == result.Name {
+					record = append(record, candidate)
+				}
+			}
+		}
+		table.Append([]string{result.Kind, result.Name, result.Namespace, status})
+	}
+	table.Render()
+
+	if failed > 0 {
+		return record, fmt.Errorf("%d of %d resources failed to prune: %w", failed, len(candidates), firstErr)
+	}
+	return record, nil
+}
+
+// confirmPrune asks on the terminal whether to delete count resources.
+func confirmPrune(count int) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, usageErrorf("stdin is not a terminal to confirm the prune, pass --yes to prune without asking")
+	}
+	fmt.Printf("Delete these %d resource(s)? [y/N] ", count)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	fmt.Println("Prune cancelled, the resources stay in the inventory")
+	return false, nil
+}
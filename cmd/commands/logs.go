@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"golang.org/x/term"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs [<pod> | deployment/<name>]",
+	Short: "Print the logs of the containers of pods",
+	Long: `Print the logs of a pod, of the pods of a deployment or of the pods
+matching --selector. Pods of a deployment are found through its selector.
+Logs of all containers are printed unless --container picks one. When the
+logs come from several pods or containers, every line is prefixed with its
+pod and container, colored on a terminal.
+
+With --follow the logs are streamed until Ctrl+C; for a deployment or a
+selector, pods that start later, such as the new pods of a rollout, are
+picked up automatically.
+
+Examples:
+  kuba logs test-pod --ns=default
+  kuba logs deployment/test-deployment -f --since=10m
+  kuba logs --selector=app=web --tail=20 -c app
+  kuba logs test-pod --previous`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		selector, _ := cmd.Flags().GetString("selector")
+		options := handlers.LogOptions{}
+		options.Container, _ = cmd.Flags().GetString("container")
+		options.Follow, _ = cmd.Flags().GetBool("follow")
+		options.Since, _ = cmd.Flags().GetDuration("since")
+		options.Tail, _ = cmd.Flags().GetInt64("tail")
+		options.Previous, _ = cmd.Flags().GetBool("previous")
+
+		if len(args) > 1 || (len(args) == 1) == (selector != "") {
+			return usageErrorf("please provide either a pod, a deployment/<name> or --selector (eg: kuba logs deployment/test-deployment)")
+		}
+		if options.Since < 0 {
+			return usageErrorf("--since must not be negative, got %s", options.Since)
+		}
+		var podName, deploymentName string
+		if len(args) == 1 {
+			kind, name, found := strings.Cut(args[0], "/")
+			switch {
+			case !found:
+				podName = kind
+			case name == "":
+				return usageErrorf("please provide a name after %q", args[0])
+			case kind == "pod" || kind == "pods" || kind == "po":
+				podName = name
+			case kind == "deployment" || kind == "deployments" || kind == "deploy":
+				deploymentName = name
+			default:
+				return usageErrorf("logs can be read from pods and deployments, got %q", kind)
+			}
+		}
+
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		colored := term.IsTerminal(int(os.Stdout.Fd()))
+		if podName != "" {
+			podDetailsList, err := handlers.PodDetailsRetrieve(client, namespace, podName)
+			if err != nil {
+				return fmt.Errorf("getting pod details: %w", err)
+			}
+			containers := podDetailsList[0].ContainerDetails
+			if options.Container != "" && !hasContainer(containers, options.Container) {
+				return usageErrorf("pod %s has no container %q, it has %s", podName, options.Container, containerNames(containers))
+			}
+			printer := newLogPrinter(colored, options.Container == "" && len(containers) > 1, false)
+			if err := handlers.StreamPodLogs(ctx, client, namespace, podName, options, printer.print); err != nil {
+				return fmt.Errorf("reading logs of pod %s: %w", podName, err)
+			}
+			return nil
+		}
+
+		if deploymentName != "" {
+			selector, err = handlers.DeploymentSelector(client, namespace, deploymentName)
+			if err != nil {
+				return fmt.Errorf("getting deployment selector: %w", err)
+			}
+		}
+		printer := newLogPrinter(colored, true, true)
+		if err := handlers.StreamSelectorLogs(ctx, client, namespace, selector, options, printer.print); err != nil {
+			return fmt.Errorf("reading logs: %w", err)
+		}
+		return nil
+	},
+}
+
+// logColors are the prefix colors, assigned to sources in turn.
+var logColors = []string{colorCyan, colorGreen, colorYellow, colorMagenta, colorBlue, colorRed}
+
+// logPrinter prints log lines, prefixed with their source when several
+// pods or containers are multiplexed.
+type logPrinter struct {
+	colored    bool
+	prefixed   bool
+	withPod    bool
+	colorIndex map[string]int
+}
+
+func newLogPrinter(colored bool, prefixed bool, withPod bool) *logPrinter {
+	return &logPrinter{colored: colored, prefixed: prefixed, withPod: withPod, colorIndex: map[string]int{}}
+}
+
+func (p *logPrinter) print(line handlers.LogLine) {
+	if !p.prefixed {
+		fmt.Println(line.Text)
+		return
+	}
+	source := line.Container
+	if p.withPod {
+		source = line.Pod + "/" + line.Container
+	}
+	if !p.colored {
+		fmt.Printf("[%s] %s\n", source, line.Text)
+		return
+	}
+	index, found := p.colorIndex[source]
+	if !found {
+		index = len(p.colorIndex)
+		p.colorIndex[source] = index
+	}
+	fmt.Printf("%s[%s]%s %s\n", logColors[index%len(logColors)], source, colorReset, line.Text)
+}
+
+func hasContainer(containers []handlers.ContainerDetails, name string) bool {
+	for _, container := range containers {
+		if container.ContainerName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func containerNames(containers []handlers.ContainerDetails) string {
+	var names []string
+	for _, container := range containers {
+		names = append(names, container.ContainerName)
+	}
+	return strings.Join(names, ", ")
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().StringP("selector", "l", "", "Read the logs of the pods matching this label selector (eg: -l app=web)")
+	logsCmd.Flags().StringP("container", "c", "", "Only read the logs of this container")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep streaming new log lines until interrupted")
+	logsCmd.Flags().Duration("since", 0, "Only print lines newer than this (eg: --since=10m)")
+	logsCmd.Flags().Int64("tail", -1, "Only print the last lines of each container, all of them when -1 (eg: --tail=20)")
+	logsCmd.Flags().Bool("previous", false, "Print the logs of the previous, terminated instance of each container")
+}
@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func addManifestFlags(command *cobra.Command) {
+	command.PersistentFlags().StringArray("fp", nil, "Manifest file or directory of .yaml, .yml and .json files; repeat for several (paths may contain commas), '-' reads stdin. (eg: --fp=./deployment.yaml)")
+	command.PersistentFlags().BoolP("recursive", "R", false, "Also read manifests from the subdirectories of directories given with --fp")
+}
+
+func manifestFlags(cmd *cobra.Command) (handlers.ManifestOptions, error) {
+	paths, _ := cmd.Flags().GetStringArray("fp")
+	recursive, _ := cmd.Flags().GetBool("recursive")
+	if len(paths) == 0 {
+		return handlers.ManifestOptions{}, usageErrorf("please provide the path of your YAML files (eg: --fp=./deployment.yaml, --fp=./manifests -R)")
+	}
+	return handlers.ManifestOptions{Paths: paths, Recursive: recursive, Stdin: os.Stdin}, nil
+}
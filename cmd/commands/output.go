@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"github.com/kanha-gupta/kuba/printers"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+func addOutputFlag(command *cobra.Command) {
+	command.PersistentFlags().StringP("output", "o", "", "Output format, one of: "+strings.Join(printers.Formats, "|")+" (eg: -o json, -o jsonpath={.name})")
+	command.PersistentFlags().Bool("raw", false, "Use the raw API objects instead of kuba's summary for json, yaml, go-template and jsonpath output")
+}
+
+func printOutput(cmd *cobra.Command, output printers.Output) error {
+	format, _ := cmd.Flags().GetString("output")
+	raw, _ := cmd.Flags().GetBool("raw")
+	if err := printers.ValidateFormat(format); err != nil {
+		return usageErrorf("%v", err)
+	}
+	if raw && output.Raw != nil {
+		data, err := output.Raw()
+		if err != nil {
+			return fmt.Errorf("getting raw objects: %w", err)
+		}
+		output.Data = data
+	}
+	if err := printers.Print(os.Stdout, format, output); err != nil {
+		return fmt.Errorf("printing output: %w", err)
+	}
+	return nil
+}
+
+// usageErrorf is cmd.UsageErrorf for command closures, where the cobra
+// command parameter shadows the cmd package.
+var usageErrorf = cmd.UsageErrorf
+
+// rawList returns an Output.Raw that lists the API objects of kinds.
+func rawList(namespace string, kinds ...string) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		dynamicClient, err := kubernetesClient.GetDynamicClient()
+		if err != nil {
+			return nil, err
+		}
+		mapper, err := kubernetesClient.GetRESTMapper()
+		if err != nil {
+			return nil, err
+		}
+		return handlers.RawObjects(dynamicClient, mapper, namespace, kinds...)
+	}
+}
+
+// rawObject returns an Output.Raw that fetches a single API object.
+func rawObject(kind string, namespace string, name string) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		dynamicClient, err := kubernetesClient.GetDynamicClient()
+		if err != nil {
+			return nil, err
+		}
+		mapper, err := kubernetesClient.GetRESTMapper()
+		if err != nil {
+			return nil, err
+		}
+		return handlers.RawObject(dynamicClient, mapper, kind, namespace, name)
+	}
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
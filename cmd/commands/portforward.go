@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// portForwardCmd represents the port-forward command
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward (<pod> | deployment/<name> | service/<name>) [local:]remote ...",
+	Short: "Forward local ports to a pod, deployment or service",
+	Long: `Forward one or more local ports to a pod until Ctrl+C. Ports are given
+as local:remote pairs; a single port forwards the same port and ":remote"
+lets the system pick a free local port.
+
+For a deployment or a service kuba forwards to one of its ready pods. Service
+ports are mapped to the target ports of the pods, as the service would do.
+When that pod goes away, for example during a rollout, kuba picks another
+ready pod and keeps forwarding from the same local ports.
+
+Examples:
+  kuba port-forward test-pod 8080:80 --ns=default
+  kuba port-forward deployment/test-deployment 8080:http 9090
+  kuba port-forward service/test-service 8080:80 :443`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		if len(args) < 2 {
+			return usageErrorf("please provide a target and at least one port (eg: kuba port-forward service/test-service 8080:80)")
+		}
+		ports, err := handlers.ParsePortPairs(args[1:])
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		config, err := kubernetesClient.GetConfig()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+
+		var target handlers.PortForwardTarget
+		kind, name, found := strings.Cut(args[0], "/")
+		switch {
+		case !found:
+			target = handlers.PortForwardTarget{Pod: kind, Ports: ports}
+		case name == "":
+			return usageErrorf("please provide a name after %q", args[0])
+		case kind == "pod" || kind == "pods" || kind == "po":
+			target = handlers.PortForwardTarget{Pod: name, Ports: ports}
+		case kind == "deployment" || kind == "deployments" || kind == "deploy":
+			target, err = handlers.DeploymentForwardTarget(client, namespace, name, ports)
+		case kind == "service" || kind == "services" || kind == "svc":
+			target, err = handlers.ServiceForwardTarget(client, namespace, name, ports)
+		default:
+			return usageErrorf("ports can be forwarded to pods, deployments and services, got %q", kind)
+		}
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", args[0], err)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		err = handlers.PortForward(ctx, config, client, namespace, target, func(event handlers.PortForwardEvent) {
+			if event.Err != nil {
+				fmt.Fprintf(os.Stderr, "Lost connection to pod %s: %v, waiting for a ready pod\n", event.Pod, event.Err)
+				return
+			}
+			for _, port := range event.Ports {
+				fmt.Printf("Forwarding from 127.0.0.1:%d -> pod %s port %s\n", port.Local, event.Pod, port.Remote)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("forwarding ports: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(portForwardCmd)
+}
@@ -0,0 +1,7 @@
+package commands
+
+import "os"
+
+// notifyResize does nothing, Windows has no signal for terminal resizes, so
+// the remote terminal keeps the size it was allocated with.
+func notifyResize(resized chan<- os.Signal) {}
@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"github.com/kanha-gupta/kuba/printers"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// rolloutCmd represents the rollout command
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Manage the rollout of deployments, statefulsets and daemonsets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageErrorf("please provide a rollout command (eg: kuba rollout status deployment/test-deployment)")
+	},
+}
+
+var rolloutStatusCmd = &cobra.Command{
+	Use:   "status <kind>/<name>",
+	Short: "Watch a rollout until it completes",
+	Long: `Watch the rollout of a deployment, statefulset or daemonset, printing its
+progress, until every replica runs the latest revision. kuba fails when a
+deployment exceeds its progress deadline or when --timeout passes.
+
+Examples:
+  kuba rollout status deployment/test-deployment --ns=default
+  kuba rollout status sts/db --timeout=10m`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		kind, name, err := parseRolloutTarget(args)
+		if err != nil {
+			return err
+		}
+
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		ctx, cancel := watchtools.ContextWithOptionalTimeout(ctx, timeout)
+		defer cancel()
+
+		err = handlers.RolloutStatus(ctx, client, kind, name, namespace, func(message string) { fmt.Println(message) })
+		if err != nil {
+			return fmt.Errorf("watching rollout of %s/%s: %w", strings.ToLower(kind), name, err)
+		}
+		return nil
+	},
+}
+
+var rolloutHistoryCmd = &cobra.Command{
+	Use:   "history <kind>/<name>",
+	Short: "List the revisions of a workload",
+	Long: `List the revisions of a deployment, statefulset or daemonset with the images
+and change-cause of each. Deployment revisions come from their replicasets,
+the others from their controller revisions.
+
+Examples:
+  kuba rollout history deployment/test-deployment --ns=default
+  kuba rollout history ds/agent -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		kind, name, err := parseRolloutTarget(args)
+		if err != nil {
+			return err
+		}
+
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		history, err := handlers.RolloutHistory(client, kind, name, namespace)
+		if err != nil {
+			return fmt.Errorf("getting rollout history: %w", err)
+		}
+
+		output := printers.Output{
+			Data:   history,
+			Header: []string{"Revision", "Change-Cause", "Images"},
+		}
+		for _, revision := range history {
+			number := strconv.FormatInt(revision.Revision, 10)
+			output.Rows = append(output.Rows, []string{number, orNone(revision.ChangeCause), revision.Images})
+			output.Names = append(output.Names, strings.ToLower(kind)+"/"+name+":"+number)
+		}
+		return printOutput(cmd, output)
+	},
+}
+
+var rolloutUndoCmd = &cobra.Command{
+	Use:   "undo <kind>/<name>",
+	Short: "Roll a workload back to an earlier revision",
+	Long: `Roll a deployment, statefulset or daemonset back to the previous revision,
+or to the one given with --to-revision. See kuba rollout history for the
+revisions.
+
+Examples:
+  kuba rollout undo deployment/test-deployment --ns=default
+  kuba rollout undo deployment/test-deployment --to-revision=3`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		toRevision, _ := cmd.Flags().GetInt64("to-revision")
+		kind, name, err := parseRolloutTarget(args)
+		if err != nil {
+			return err
+		}
+		if toRevision < 0 {
+			return usageErrorf("--to-revision must be a revision number, got %d", toRevision)
+		}
+
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		revision, err := handlers.RolloutUndo(client, kind, name, namespace, toRevision)
+		if err != nil {
+			return fmt.Errorf("rolling back %s/%s: %w", strings.ToLower(kind), name, err)
+		}
+		fmt.Printf("%s/%s rolled back to revision %d\n", strings.ToLower(kind), name, revision)
+		return nil
+	},
+}
+
+var rolloutRestartCmd = &cobra.Command{
+	Use:   "restart <kind>/<name>",
+	Short: "Restart the pods of a workload",
+	Long: `Restart every pod of a deployment, statefulset or daemonset with a rolling
+update, by stamping the pod template with the restart time.
+
+Examples:
+  kuba rollout restart deployment/test-deployment --ns=default`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		kind, name, err := parseRolloutTarget(args)
+		if err != nil {
+			return err
+		}
+
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		if err := handlers.RolloutRestart(client, kind, name, namespace, time.Now()); err != nil {
+			return fmt.Errorf("restarting %s/%s: %w", strings.ToLower(kind), name, err)
+		}
+		fmt.Printf("%s/%s restarted\n", strings.ToLower(kind), name)
+		return nil
+	},
+}
+
+// parseRolloutTarget parses the single "<kind>/<name>" argument of the
+// rollout commands.
+func parseRolloutTarget(args []string) (string, string, error) {
+	if len(args) != 1 {
+		return "", "", usageErrorf("please provide one workload as <kind>/<name> (eg: deployment/test-deployment)")
+	}
+	kind, name, found := strings.Cut(args[0], "/")
+	if !found || kind == "" || name == "" {
+		return "", "", usageErrorf("please provide the workload as <kind>/<name>, got %q", args[0])
+	}
+	kind, err := handlers.ParseRolloutKind(kind)
+	return kind, name, err
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(rolloutCmd)
+	rolloutCmd.AddCommand(rolloutStatusCmd, rolloutHistoryCmd, rolloutUndoCmd, rolloutRestartCmd)
+	addTimeoutFlag(rolloutStatusCmd, "How long to wait for the rollout before giving up, 0 waits forever (eg: --timeout=10m)")
+	addOutputFlag(rolloutHistoryCmd)
+	rolloutUndoCmd.Flags().Int64("to-revision", 0, "The revision to roll back to, the previous one by default")
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// scaleCmd represents the scale command
+var scaleCmd = &cobra.Command{
+	Use:   "scale <kind>/<name> --replicas=<count>",
+	Short: "Set the number of replicas of a workload",
+	Long: `Set the replicas of a deployment, statefulset, replicaset or any other
+resource served with a scale subresource, including custom resources.
+
+kuba refuses to scale a workload targeted by a HorizontalPodAutoscaler, which
+would soon undo the change; --force scales it anyway. --current-replicas
+only scales when the workload has that many replicas, and --wait watches it
+until that many replicas are ready.
+
+Examples:
+  kuba scale deployment/test-deployment --replicas=3 --ns=default
+  kuba scale sts/db --replicas=5 --current-replicas=3
+  kuba scale deployment/test-deployment --replicas=0 --wait --timeout=2m`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		replicas, _ := cmd.Flags().GetInt64("replicas")
+		currentReplicas, _ := cmd.Flags().GetInt64("current-replicas")
+		force, _ := cmd.Flags().GetBool("force")
+		waitForReady, _ := cmd.Flags().GetBool("wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if len(args) != 1 {
+			return usageErrorf("please provide one resource as <kind>/<name> (eg: kuba scale deployment/test-deployment --replicas=3)")
+		}
+		kind, name, found := strings.Cut(args[0], "/")
+		if !found || kind == "" || name == "" {
+			return usageErrorf("please provide the resource as <kind>/<name>, got %q", args[0])
+		}
+		if replicas < 0 {
+			return usageErrorf("please provide the number of replicas (eg: --replicas=3)")
+		}
+
+		dynamicClient, err := kubernetesClient.GetDynamicClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		mapper, err := kubernetesClient.GetRESTMapper()
+		if err != nil {
+			return fmt.Errorf("discovering cluster resources: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		result, err := handlers.ScaleResource(ctx, dynamicClient, mapper, kind, name, namespace, replicas, currentReplicas, force)
+		if err != nil {
+			return fmt.Errorf("scaling %s: %w", args[0], err)
+		}
+		if len(result.Autoscalers) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %s is scaled by HorizontalPodAutoscaler %s, which may change its replicas again\n",
+				args[0], strings.Join(result.Autoscalers, ", "))
+		}
+		fmt.Printf("%s scaled from %d to %d replicas\n", args[0], result.PreviousReplicas, result.Replicas)
+		if !waitForReady {
+			return nil
+		}
+
+		ctx, cancel := watchtools.ContextWithOptionalTimeout(ctx, timeout)
+		defer cancel()
+		if err := handlers.WaitForScaled(ctx, dynamicClient, mapper, kind, name, namespace, replicas); err != nil {
+			return fmt.Errorf("waiting for %s: %w", args[0], err)
+		}
+		fmt.Printf("%s has %d ready replicas\n", args[0], replicas)
+		return nil
+	},
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(scaleCmd)
+	scaleCmd.Flags().Int64("replicas", -1, "The number of replicas to scale to")
+	scaleCmd.Flags().Int64("current-replicas", -1, "Only scale when the resource has this many replicas")
+	scaleCmd.Flags().Bool("force", false, "Scale even when a HorizontalPodAutoscaler targets the resource")
+	scaleCmd.Flags().Bool("wait", false, "Wait until the ready replicas match --replicas")
+	addTimeoutFlag(scaleCmd, "How long --wait waits before giving up, 0 waits forever (eg: --timeout=2m)")
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// setCmd represents the set command
+var setCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Change fields of workloads",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageErrorf("please provide what to set (eg: kuba set image deployment/test-deployment app=nginx:1.25)")
+	},
+}
+
+var setImageCmd = &cobra.Command{
+	Use:   "image [<kind>/<name>] <container>=<image> ...",
+	Short: "Set the container images of a workload or of local manifests",
+	Long: `Set the images of containers in the pod template of a deployment, statefulset
+or daemonset, which starts a rollout. The command is recorded in the
+kubernetes.io/change-cause annotation, so it shows in kuba rollout history.
+
+With --fp the image fields of local manifests are rewritten in place
+instead, keeping comments and formatting; a <kind>/<name> then limits the
+change to that object. Containers are matched by name, and unknown container
+names are refused.
+
+Examples:
+  kuba set image deployment/test-deployment app=nginx:1.25 --ns=default
+  kuba set image sts/db postgres=postgres:16 init-schema=migrate:2
+  kuba set image app=registry.example.com/web:2024.05.1 --fp=./manifests -R`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		kind, name, images, err := parseSetImageArgs(args)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("fp") {
+			manifests, err := manifestFlags(cmd)
+			if err != nil {
+				return err
+			}
+			return setManifestImages(manifests, kind, name, images)
+		}
+		if kind == "" {
+			return usageErrorf("please provide the workload as <kind>/<name>, or local manifests with --fp")
+		}
+		if kind, err = handlers.ParseRolloutKind(kind); err != nil {
+			return err
+		}
+
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		containers, err := handlers.WorkloadContainers(client, kind, name, namespace)
+		if err != nil {
+			return fmt.Errorf("getting containers of %s: %w", args[0], err)
+		}
+		if err := checkImageContainers(args[0], containers, images); err != nil {
+			return err
+		}
+
+		changeCause := "kuba set image " + strings.Join(args, " ")
+		if err := handlers.SetImages(client, kind, name, namespace, images, changeCause); err != nil {
+			return fmt.Errorf("setting images of %s: %w", args[0], err)
+		}
+		fmt.Printf("%s images updated: %s\n", args[0], strings.Join(args[1:], " "))
+		return nil
+	},
+}
+
+// parseSetImageArgs splits the arguments of set image into the optional
+// <kind>/<name> and the <container>=<image> pairs.
+func parseSetImageArgs(args []string) (string, string, []handlers.ContainerImage, error) {
+	var kind, name string
+	if len(args) > 0 && !strings.Contains(args[0], "=") {
+		var found bool
+		kind, name, found = strings.Cut(args[0], "/")
+		if !found || kind == "" || name == "" {
+			return "", "", nil, usageErrorf("please provide the workload as <kind>/<name>, got %q", args[0])
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return "", "", nil, usageErrorf("please provide the images to set as <container>=<image> (eg: app=nginx:1.25)")
+	}
+
+	var images []handlers.ContainerImage
+	seen := map[string]bool{}
+	for _, arg := range args {
+		container, image, found := strings.Cut(arg, "=")
+		if !found || container == "" || image == "" {
+			return "", "", nil, usageErrorf("please provide images as <container>=<image>, got %q", arg)
+		}
+		if seen[container] {
+			return "", "", nil, usageErrorf("container %q is given more than once", container)
+		}
+		seen[container] = true
+		images = append(images, handlers.ContainerImage{Container: container, Image: image})
+	}
+	return kind, name, images, nil
+}
+
+// checkImageContainers refuses images for containers that target does not
+// have.
+func checkImageContainers(target string, containers []handlers.ContainerDetails, images []handlers.ContainerImage) error {
+	for _, image := range images {
+		if !hasContainer(containers, image.Container) {
+			if len(containers) == 0 {
+				return usageErrorf("%s has no containers", target)
+			}
+			return usageErrorf("%s has no container %q, it has %s", target, image.Container, containerNames(containers))
+		}
+	}
+	return nil
+}
+
+func setManifestImages(manifests handlers.ManifestOptions, kind string, name string, images []handlers.ContainerImage) error {
+	target := strings.Join(manifests.Paths, ", ")
+	if kind != "" {
+		target = fmt.Sprintf("%s/%s in %s", kind, name, target)
+	}
+	containers, err := handlers.ManifestContainers(manifests, kind, name)
+	if err != nil {
+		return fmt.Errorf("reading manifests: %w", err)
+	}
+	if err := checkImageContainers(target, containers, images); err != nil {
+		return err
+	}
+
+	results, err := handlers.SetManifestImages(manifests, kind, name, images)
+	changed := 0
+	for _, result := range results {
+		if result.Changed > 0 {
+			fmt.Printf("%s: %d image(s) updated\n", result.File, result.Changed)
+			changed += result.Changed
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("rewriting manifests: %w", err)
+	}
+	if changed == 0 {
+		fmt.Println("Images are already up to date")
+	}
+	return nil
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(setCmd)
+	setCmd.AddCommand(setImageCmd)
+	addManifestFlags(setImageCmd)
+}
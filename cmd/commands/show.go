@@ -4,13 +4,15 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package commands
 
 import (
+	"context"
+	"fmt"
 	"github.com/kanha-gupta/kuba/cmd"
 	"github.com/kanha-gupta/kuba/handlers"
 	"github.com/kanha-gupta/kuba/kubernetesClient"
-	"github.com/olekukonko/tablewriter"
+	"github.com/kanha-gupta/kuba/printers"
 	"github.com/spf13/cobra"
-	"log"
-	"os"
+	"strconv"
+	"strings"
 )
 
 var Verbose bool
@@ -18,89 +20,195 @@ var Source string
 var showCmd = &cobra.Command{
 	Use:   "show",
 	Short: "It show the rosurce details metion in the command (like.. pods, servcies, deployments, etc...)",
-	Run: func(cmd *cobra.Command, args []string) {
-		log.Print("Please mention the name of resorces you want to see")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return usageErrorf("please mention the name of the resources you want to see (eg: kuba show pods)")
 	},
 }
 
 var allCmd = &cobra.Command{
 	Use:   "all",
 	Short: "Get all resources from provided namespace",
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Get the deployments and services of a namespace, or of every namespace
+with --ns="". With --watch the table is redrawn whenever one of them is
+added, modified or deleted, highlighting the rows that changed; stop it
+with Ctrl+C.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		namespace, _ := cmd.Flags().GetString("ns")
 		client, err := kubernetesClient.GetClient()
 		if err != nil {
-			log.Printf("error getting kubernetes k8_client: %v", err)
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			return watchOutput(cmd, func(ctx context.Context, render func(printers.Output)) error {
+				return handlers.WatchResourceInfos(ctx, client, namespace, func(resources []handlers.ResourceInfo) {
+					render(resourcesOutput(namespace, resources))
+				})
+			})
 		}
 		resources, err := handlers.ResourceInfos(client, namespace)
 		if err != nil {
-			log.Printf("error getting resources: %v", err)
-		} else {
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"Resource Type", "Name", "Namespace", "Created At"})
-
-			for _, resource := range resources {
-				createdTime := resource.CreatedAt.Format("2006-01-02 15:04:05")
-				row := []string{resource.Kind, resource.Name, resource.Namespace, createdTime}
-				table.Append(row)
-			}
-			table.Render()
+			return fmt.Errorf("getting resources: %w", err)
 		}
+		return printOutput(cmd, resourcesOutput(namespace, resources))
 	},
 }
 
+func resourcesOutput(namespace string, resources []handlers.ResourceInfo) printers.Output {
+	output := printers.Output{
+		Data:   resources,
+		Raw:    rawList(namespace, "deployments", "services"),
+		Header: []string{"Resource Type", "Name", "Namespace", "Created At"},
+	}
+
+	for _, resource := range resources {
+		createdTime := resource.CreatedAt.Format("2006-01-02 15:04:05")
+		row := []string{resource.Kind, resource.Name, resource.Namespace, createdTime}
+		output.Rows = append(output.Rows, row)
+		output.Names = append(output.Names, strings.ToLower(resource.Kind)+"/"+resource.Name)
+	}
+	return output
+}
+
 var deploymentCmd = &cobra.Command{
 	Use:   "deploy",
 	Short: "Show deployments in a Kubernetes namespace",
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Show the deployments of a namespace. With --watch the table is redrawn
+whenever a deployment is added, modified or deleted, highlighting the rows
+that changed; stop it with Ctrl+C.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		namespace, _ := cmd.Flags().GetString("ns")
 		client, err := kubernetesClient.GetClient()
 		if err != nil {
-			log.Printf("error getting kubernetes k8_client: %v", err)
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			return watchOutput(cmd, func(ctx context.Context, render func(printers.Output)) error {
+				return handlers.WatchDeployments(ctx, client, namespace, func(deploymentList []handlers.DeploymentInfo) {
+					render(deploymentsOutput(namespace, deploymentList))
+				})
+			})
 		}
 		deploymentList, err := handlers.ShowDeployments(client, namespace)
 		if err != nil {
-			log.Printf("error getting deployment list: %v", err)
-		} else {
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"Deployment", "Namespace", "Ready", "Age"})
+			return fmt.Errorf("getting deployment list: %w", err)
+		}
+		return printOutput(cmd, deploymentsOutput(namespace, deploymentList))
+	},
+}
+
+func deploymentsOutput(namespace string, deploymentList []handlers.DeploymentInfo) printers.Output {
+	output := printers.Output{
+		Data:       deploymentList,
+		Raw:        rawList(namespace, "deployments"),
+		Header:     []string{"Deployment", "Namespace", "Ready", "Up-To-Date", "Available", "Age"},
+		WideHeader: []string{"Containers", "Images", "Selector"},
+	}
+
+	for _, deployment := range deploymentList {
+		row := []string{deployment.Name, deployment.Namespace, deployment.Ready,
+			strconv.Itoa(int(deployment.UpToDate)), strconv.Itoa(int(deployment.Available)), deployment.Age}
+		output.Rows = append(output.Rows, row)
+		output.WideRows = append(output.WideRows, []string{deployment.Containers, deployment.Images, orNone(deployment.Selector)})
+		output.Names = append(output.Names, "deployment/"+deployment.Name)
+	}
+	return output
+}
+
+var podsCmd = &cobra.Command{
+	Use:     "pods",
+	Aliases: []string{"pod", "po"},
+	Short:   "Show pods in a Kubernetes namespace",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		podList, err := handlers.ShowPods(client, namespace)
+		if err != nil {
+			return fmt.Errorf("getting pod list: %w", err)
+		}
+		output := printers.Output{
+			Data:   podList,
+			Raw:    rawList(namespace, "pods"),
+			Header: []string{"Pod", "Namespace", "Ready", "Status", "Restarts", "Age", "Node", "IP"},
+		}
 
-			for _, deployment := range deploymentList {
-				row := []string{deployment.Name, deployment.Namespace, deployment.Ready, deployment.Age}
-				table.Append(row)
-			}
-			table.Render()
+		for _, pod := range podList {
+			row := []string{pod.Name, pod.Namespace, pod.Ready, pod.Status, strconv.Itoa(int(pod.Restarts)), pod.Age, pod.Node, pod.IP}
+			output.Rows = append(output.Rows, row)
+			output.Names = append(output.Names, "pod/"+pod.Name)
 		}
+		return printOutput(cmd, output)
+	},
+}
+
+var servicesCmd = &cobra.Command{
+	Use:     "services",
+	Aliases: []string{"service", "svc"},
+	Short:   "Show services in a Kubernetes namespace",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		client, err := kubernetesClient.GetClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		serviceList, err := handlers.ShowServices(client, namespace)
+		if err != nil {
+			return fmt.Errorf("getting service list: %w", err)
+		}
+		output := printers.Output{
+			Data:       serviceList,
+			Raw:        rawList(namespace, "services"),
+			Header:     []string{"Service", "Namespace", "Type", "Cluster-IP", "External-IP", "Ports", "Age"},
+			WideHeader: []string{"Selector"},
+		}
+
+		for _, service := range serviceList {
+			row := []string{service.Name, service.Namespace, service.Type, service.ClusterIP, service.ExternalIP, service.Ports, service.Age}
+			output.Rows = append(output.Rows, row)
+			output.WideRows = append(output.WideRows, []string{orNone(service.Selector)})
+			output.Names = append(output.Names, "service/"+service.Name)
+		}
+		return printOutput(cmd, output)
 	},
 }
 
 var namespaceCmd = &cobra.Command{
 	Use:   "namespaces",
 	Short: "It will show all name-spaces in kubernetes cluster",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := kubernetesClient.GetClient()
 		if err != nil {
-			log.Printf("error getting kubernetes k8_client: %v", err)
+			return fmt.Errorf("getting kubernetes client: %w", err)
 		}
 		namespaceDetails, err := handlers.NameSpaceShower(client)
 		if err != nil {
-			log.Printf("Can't get the namespacces: %v", err)
-		} else {
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"Namespace-Name", "status", "Age"})
+			return fmt.Errorf("getting namespaces: %w", err)
+		}
+		output := printers.Output{
+			Data:   namespaceDetails,
+			Raw:    rawList("", "namespaces"),
+			Header: []string{"Namespace-Name", "status", "Age"},
+		}
 
-			for _, namespace := range namespaceDetails {
-				row := []string{namespace.Name, namespace.Status, namespace.Age}
-				table.Append(row)
-			}
-			table.Render()
+		for _, namespace := range namespaceDetails {
+			row := []string{namespace.Name, namespace.Status, namespace.Age}
+			output.Rows = append(output.Rows, row)
+			output.Names = append(output.Names, "namespace/"+namespace.Name)
 		}
+		return printOutput(cmd, output)
 	},
 }
 
 func init() {
 	cmd.RootCmd.AddCommand(showCmd)
+	addOutputFlag(showCmd)
 	showCmd.AddCommand(allCmd)
 	showCmd.AddCommand(namespaceCmd)
 	showCmd.AddCommand(deploymentCmd)
+	showCmd.AddCommand(podsCmd)
+	showCmd.AddCommand(servicesCmd)
+	addWatchFlag(allCmd)
+	addWatchFlag(deploymentCmd)
 }
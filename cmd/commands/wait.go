@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kanha-gupta/kuba/cmd"
+	"github.com/kanha-gupta/kuba/handlers"
+	"github.com/kanha-gupta/kuba/kubernetesClient"
+	"github.com/olekukonko/tablewriter"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// waitCmd represents the wait command
+var waitCmd = &cobra.Command{
+	Use:   "wait <kind>/<name> ...",
+	Short: "Wait until Kubernetes resources are ready or meet a condition",
+	Long: `Watch one or more resources until they are ready. By default readiness
+depends on the kind: deployments have all replicas updated and available,
+jobs are complete, pods are Ready and services with a selector have
+endpoints; other kinds are ready once they exist. With --for=condition=<type>[=<status>] kuba waits for
+a status condition instead (status defaults to True).
+
+Examples:
+  kuba wait deployment/test-deployment --ns=default
+  kuba wait --for=condition=Available deployment/test-deployment --timeout=2m
+  kuba wait --for=condition=Complete job/migrate job/seed`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		namespace, _ := cmd.Flags().GetString("ns")
+		forValue, _ := cmd.Flags().GetString("for")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if len(args) == 0 {
+			return usageErrorf("please provide the resources to wait for (eg: kuba wait deployment/test-deployment)")
+		}
+
+		conditionType, conditionStatus, err := parseWaitFor(forValue)
+		if err != nil {
+			return err
+		}
+		type target struct{ kind, name string }
+		var targets []target
+		for _, arg := range args {
+			kind, name, found := strings.Cut(arg, "/")
+			if !found || kind == "" || name == "" {
+				return usageErrorf("please provide resources as <kind>/<name>, got %q", arg)
+			}
+			targets = append(targets, target{kind, name})
+		}
+
+		dynamicClient, err := kubernetesClient.GetDynamicClient()
+		if err != nil {
+			return fmt.Errorf("getting kubernetes client: %w", err)
+		}
+		mapper, err := kubernetesClient.GetRESTMapper()
+		if err != nil {
+			return fmt.Errorf("discovering cluster resources: %w", err)
+		}
+
+		ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), timeout)
+		defer cancel()
+
+		for _, target := range targets {
+			if conditionType == "" {
+				err = handlers.WaitForReady(ctx, dynamicClient, mapper, target.kind, target.name, namespace)
+			} else {
+				err = handlers.WaitForCondition(ctx, dynamicClient, mapper, target.kind, target.name, namespace, conditionType, conditionStatus)
+			}
+			if err != nil {
+				return fmt.Errorf("waiting for %s/%s: %w", target.kind, target.name, err)
+			}
+			fmt.Printf("%s/%s condition met\n", target.kind, target.name)
+		}
+		return nil
+	},
+}
+
+// parseWaitFor splits a --for value: "ready" waits for kind-specific
+// readiness, "condition=<type>[=<status>]" for a status condition.
+func parseWaitFor(value string) (string, string, error) {
+	if value == "" || value == "ready" {
+		return "", "", nil
+	}
+	condition, found := strings.CutPrefix(value, "condition=")
+	if !found || condition == "" {
+		return "", "", usageErrorf("invalid --for %q, expected ready or condition=<type>[=<status>]", value)
+	}
+	conditionType, conditionStatus, _ := strings.Cut(condition, "=")
+	return conditionType, conditionStatus, nil
+}
+
+func addTimeoutFlag(command *cobra.Command, usage string) {
+	command.PersistentFlags().Duration("timeout", 5*time.Minute, usage)
+}
+
+// waitForCreated waits for the created objects and prints whether each one
+// became ready.
+func waitForCreated(cmd *cobra.Command, results []handlers.CreateResult) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	dynamicClient, err := kubernetesClient.GetDynamicClient()
+	if err != nil {
+		return fmt.Errorf("getting kubernetes client: %w", err)
+	}
+	mapper, err := kubernetesClient.GetRESTMapper()
+	if err != nil {
+		return fmt.Errorf("discovering cluster resources: %w", err)
+	}
+
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), timeout)
+	defer cancel()
+
+	fmt.Printf("Waiting up to %s for %d resource(s) to become ready\n", timeout, len(results))
+	waits := handlers.WaitForCreated(ctx, dynamicClient, mapper, results)
+
+	failed := 0
+	var firstErr error
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Name", "Namespace", "Ready"})
+	for _, result := range waits {
+		status := "ready"
+		if result.Err != nil {
+			status = result.Err.Error()
+			failed++
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+		}
+		table.Append([]string{result.Kind, result.Name, result.Namespace, status})
+	}
+	table.Render()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d resources did not become ready: %w", failed, len(waits), firstErr)
+	}
+	return nil
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(waitCmd)
+	waitCmd.PersistentFlags().String("for", "ready", "What to wait for: ready, or condition=<type>[=<status>] (eg: --for=condition=Available)")
+	addTimeoutFlag(waitCmd, "How long to wait before giving up, 0 waits forever (eg: --timeout=30s)")
+}
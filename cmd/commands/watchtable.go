@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kanha-gupta/kuba/printers"
+	"golang.org/x/term"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+func addWatchFlag(command *cobra.Command) {
+	command.Flags().BoolP("watch", "w", false, "Keep watching and re-render the table when objects are added, modified or deleted")
+}
+
+// watchOutput prints every output passed to render until the watch ends or
+// kuba is interrupted. On a terminal, tables are redrawn in place and the
+// rows that changed since the previous render are highlighted; otherwise
+// each render is printed after the previous one.
+func watchOutput(cmd *cobra.Command, watch func(ctx context.Context, render func(printers.Output)) error) error {
+	format, _ := cmd.Flags().GetString("output")
+	if err := printers.ValidateFormat(format); err != nil {
+		return usageErrorf("%v", err)
+	}
+	inPlace := term.IsTerminal(int(os.Stdout.Fd())) && (format == "" || format == "table" || format == "wide")
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	var previous map[string]bool
+	var printErr error
+	err := watch(ctx, func(output printers.Output) {
+		if printErr != nil {
+			return
+		}
+		if inPlace {
+			fmt.Print(clearScreen)
+			previous = highlightChanged(&output, previous)
+		}
+		printErr = printOutput(cmd, output)
+		if printErr != nil {
+			stop()
+		}
+	})
+	if printErr != nil {
+		return printErr
+	}
+	return err
+}
+
+// highlightChanged marks the rows of output that are not in previous, the
+// rows seen by the last render, and returns the rows of this render. Age
+// columns are left out of the comparison since they change on their own.
+func highlightChanged(output *printers.Output, previous map[string]bool) map[string]bool {
+	current := map[string]bool{}
+	for i, row := range output.Rows {
+		var cells []string
+		for j, cell := range row {
+			if j < len(output.Header) && strings.EqualFold(output.Header[j], "Age") {
+				continue
+			}
+			cells = append(cells, cell)
+		}
+		key := strings.Join(cells, "\x00")
+		current[key] = true
+		if previous == nil || previous[key] {
+			continue
+		}
+		output.Rows[i] = highlightRow(row)
+		if i < len(output.WideRows) {
+			output.WideRows[i] = highlightRow(output.WideRows[i])
+		}
+	}
+	return current
+}
+
+func highlightRow(row []string) []string {
+	highlighted := make([]string, len(row))
+	for i, cell := range row {
+		highlighted[i] = colorBold + colorGreen + cell + colorReset
+	}
+	return highlighted
+}
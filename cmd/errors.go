@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kanha-gupta/kuba/handlers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Exit codes returned by kuba, so that scripts can tell failures apart.
+const (
+	ExitGeneralError    = 1
+	ExitUsageError      = 2
+	ExitNotFound        = 3
+	ExitForbidden       = 4
+	ExitConflict        = 5
+	ExitUnsupportedKind = 6
+	ExitInvalidManifest = 7
+	ExitNotReady        = 8
+)
+
+// UsageError reports a command run with missing or invalid flags.
+type UsageError struct {
+	message string
+}
+
+func (e *UsageError) Error() string {
+	return e.message
+}
+
+// UsageErrorf returns a UsageError with a formatted message.
+func UsageErrorf(format string, args ...interface{}) error {
+	return &UsageError{message: fmt.Sprintf(format, args...)}
+}
+
+// ExitError ends kuba with Code, for commands whose exit code is part of
+// their result, such as diff. Without Err nothing is printed.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode maps an error returned by a command to the process exit code.
+func ExitCode(err error) int {
+	var usageErr *UsageError
+	var exitErr *ExitError
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	case errors.As(err, &usageErr):
+		return ExitUsageError
+	case errors.Is(err, handlers.ErrUnsupportedKind):
+		return ExitUnsupportedKind
+	case errors.Is(err, handlers.ErrInvalidManifest):
+		return ExitInvalidManifest
+	case errors.Is(err, handlers.ErrWaitTimeout), errors.Is(err, handlers.ErrNotReady):
+		return ExitNotReady
+	case apierrors.IsNotFound(err):
+		return ExitNotFound
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return ExitForbidden
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err), errors.Is(err, handlers.ErrAutoscaled):
+		return ExitConflict
+	default:
+		return ExitGeneralError
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kanha-gupta/kuba/handlers"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestExitCode(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"general", errors.New("boom"), ExitGeneralError},
+		{"exit", &ExitError{Code: 1}, 1},
+		{"exit with error", &ExitError{Code: 2, Err: apierrors.NewNotFound(pods, "web")}, 2},
+		{"usage", UsageErrorf("please provide --fp"), ExitUsageError},
+		{"not found", fmt.Errorf("getting pod details: %w", apierrors.NewNotFound(pods, "web")), ExitNotFound},
+		{"forbidden", apierrors.NewForbidden(pods, "web", errors.New("denied")), ExitForbidden},
+		{"unauthorized", apierrors.NewUnauthorized("no credentials"), ExitForbidden},
+		{"conflict", apierrors.NewConflict(pods, "web", errors.New("modified")), ExitConflict},
+		{"already exists", apierrors.NewAlreadyExists(pods, "web"), ExitConflict},
+		{"autoscaled", fmt.Errorf("scaling deployment/web: %w", handlers.ErrAutoscaled), ExitConflict},
+		{"unsupported kind", fmt.Errorf("deleting resource: %w", fmt.Errorf("%w: gadget", handlers.ErrUnsupportedKind)), ExitUnsupportedKind},
+		{"wait timeout", fmt.Errorf("%w for deployments web", handlers.ErrWaitTimeout), ExitNotReady},
+		{"not ready", fmt.Errorf("%w: job migrate failed", handlers.ErrNotReady), ExitNotReady},
+		{"invalid manifest", fmt.Errorf("%w ./app.yaml: bad", handlers.ErrInvalidManifest), ExitInvalidManifest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ExitCode(test.err); got != test.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", test.err, got, test.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,11 @@ Copyright © 2024 Kanha Gupta
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
+	"github.com/kanha-gupta/kuba/kubernetesClient"
 	"github.com/spf13/cobra"
 )
 
@@ -22,14 +25,27 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+
+	// Errors are printed by Execute, which also picks the exit code.
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := RootCmd.Execute()
+	command, err := RootCmd.ExecuteC()
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Err == nil {
+		os.Exit(exitErr.Code)
+	}
+	var usageErr *UsageError
 	if err != nil {
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		if errors.As(err, &usageErr) {
+			fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", command.CommandPath())
+		}
+		os.Exit(ExitCode(err))
 	}
 }
 
@@ -43,5 +59,9 @@ func init() {
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	RootCmd.PersistentFlags().String("ns", "", "You can provide kubernets namspace (eg: --ns=default)")
+	kubernetesClient.Flags.AddFlags(RootCmd.PersistentFlags())
+	RootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &UsageError{message: err.Error()}
+	})
 	RootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
@@ -9,36 +9,36 @@ import (
 )
 
 type PodDetails struct {
-	Name             string
-	Namespace        string
-	CreationTime     time.Time
-	Phase            string
-	Conditions       []PodCondition
-	IP               string
-	ContainerDetails []ContainerDetails
+	Name             string             `json:"name"`
+	Namespace        string             `json:"namespace"`
+	CreationTime     time.Time          `json:"creationTime"`
+	Phase            string             `json:"phase"`
+	Conditions       []PodCondition     `json:"conditions"`
+	IP               string             `json:"ip"`
+	ContainerDetails []ContainerDetails `json:"containerDetails"`
 }
 
 type PodCondition struct {
-	Type               string
-	Status             string
-	LastTransitionTime time.Time
-	Reason             string
-	Message            string
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+	Reason             string    `json:"reason"`
+	Message            string    `json:"message"`
 }
 
 type ContainerDetails struct {
-	ContainerName string
-	Ports         []PortDetails
+	ContainerName string        `json:"containerName"`
+	Ports         []PortDetails `json:"ports"`
 }
 
 type PortDetails struct {
-	PortName      string
-	Protocol      string
-	ContainerPort int32
-	HostPort      int32
+	PortName      string `json:"portName"`
+	Protocol      string `json:"protocol"`
+	ContainerPort int32  `json:"containerPort"`
+	HostPort      int32  `json:"hostPort"`
 }
 
-func PodDetailsRetrieve(clientset *kubernetes.Clientset, namespace string, podName string) ([]PodDetails, error) {
+func PodDetailsRetrieve(clientset kubernetes.Interface, namespace string, podName string) ([]PodDetails, error) {
 	poddetail, err := clientset.CoreV1().Pods(namespace).Get(context.TODO(), podName, v1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -89,19 +89,19 @@ func PodDetailsRetrieve(clientset *kubernetes.Clientset, namespace string, podNa
 }
 
 type DeploymentDetails struct {
-	Name              string
-	Namespace         string
-	CreationTime      time.Time
-	Replicas          int32
-	AvailableReplicas int32
-	ReadyReplicas     int32
-	UpdatedReplicas   int32
-	Strategy          string
-	Selector          string
-	Containers        []ContainerDetails
+	Name              string             `json:"name"`
+	Namespace         string             `json:"namespace"`
+	CreationTime      time.Time          `json:"creationTime"`
+	Replicas          int32              `json:"replicas"`
+	AvailableReplicas int32              `json:"availableReplicas"`
+	ReadyReplicas     int32              `json:"readyReplicas"`
+	UpdatedReplicas   int32              `json:"updatedReplicas"`
+	Strategy          string             `json:"strategy"`
+	Selector          string             `json:"selector"`
+	Containers        []ContainerDetails `json:"containers"`
 }
 
-func GetDeploymentDetails(clientset *kubernetes.Clientset, namespace string, deploymentName string) ([]DeploymentDetails, error) {
+func GetDeploymentDetails(clientset kubernetes.Interface, namespace string, deploymentName string) ([]DeploymentDetails, error) {
 	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, v1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -160,15 +160,15 @@ func getLabelSelector(selector *v1.LabelSelector) string {
 }
 
 type NamespaceDetails struct {
-	Name          string
-	CreationTime  time.Time
-	Status        string
-	Labels        map[string]string
-	Annotations   map[string]string
-	ResourceQuota string
+	Name          string            `json:"name"`
+	CreationTime  time.Time         `json:"creationTime"`
+	Status        string            `json:"status"`
+	Labels        map[string]string `json:"labels"`
+	Annotations   map[string]string `json:"annotations"`
+	ResourceQuota string            `json:"resourceQuota"`
 }
 
-func NameSpaceDetailsRetrieve(clientset *kubernetes.Clientset, namespace string) ([]NamespaceDetails, error) {
+func NameSpaceDetailsRetrieve(clientset kubernetes.Interface, namespace string) ([]NamespaceDetails, error) {
 	ns, err := clientset.CoreV1().Namespaces().Get(context.TODO(), namespace, v1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -190,7 +190,7 @@ func NameSpaceDetailsRetrieve(clientset *kubernetes.Clientset, namespace string)
 	return nsDetailsList, nil
 }
 
-func getResourceQuota(clientset *kubernetes.Clientset, namespace string) string {
+func getResourceQuota(clientset kubernetes.Interface, namespace string) string {
 	quota, err := clientset.CoreV1().ResourceQuotas(namespace).List(context.TODO(), v1.ListOptions{})
 	if err != nil {
 		return ""
@@ -204,28 +204,28 @@ func getResourceQuota(clientset *kubernetes.Clientset, namespace string) string
 }
 
 type ServiceDetails struct {
-	Name            string
-	Namespace       string
-	CreationTime    time.Time
-	Labels          map[string]string
-	Type            string
-	ClusterIP       string
-	ExternalIPs     []string
-	LoadBalancerIP  string
-	Ports           []ServicePortDetails
-	Selector        map[string]string
-	SessionAffinity string
+	Name            string               `json:"name"`
+	Namespace       string               `json:"namespace"`
+	CreationTime    time.Time            `json:"creationTime"`
+	Labels          map[string]string    `json:"labels"`
+	Type            string               `json:"type"`
+	ClusterIP       string               `json:"clusterIP"`
+	ExternalIPs     []string             `json:"externalIPs"`
+	LoadBalancerIP  string               `json:"loadBalancerIP"`
+	Ports           []ServicePortDetails `json:"ports"`
+	Selector        map[string]string    `json:"selector"`
+	SessionAffinity string               `json:"sessionAffinity"`
 }
 
 type ServicePortDetails struct {
-	Name       string
-	Protocol   string
-	Port       int32
-	TargetPort string
-	NodePort   int32
+	Name       string `json:"name"`
+	Protocol   string `json:"protocol"`
+	Port       int32  `json:"port"`
+	TargetPort string `json:"targetPort"`
+	NodePort   int32  `json:"nodePort"`
 }
 
-func ServiceDetailsRetrieve(clientset *kubernetes.Clientset, namespace string, serviceName string) ([]ServiceDetails, error) {
+func ServiceDetailsRetrieve(clientset kubernetes.Interface, namespace string, serviceName string) ([]ServiceDetails, error) {
 	service, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), serviceName, v1.GetOptions{})
 	if err != nil {
 		return nil, err
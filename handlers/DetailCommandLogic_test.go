@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func TestPodDetailsRetrieve(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "web",
+				Ports: []corev1.ContainerPort{{Name: "http", Protocol: corev1.ProtocolTCP, ContainerPort: 8080, HostPort: 80}},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			PodIP: "10.0.0.7",
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, Reason: "Started"},
+			},
+		},
+	})
+
+	podDetailsList, err := PodDetailsRetrieve(clientset, "default", "web-0")
+	if err != nil {
+		t.Fatalf("PodDetailsRetrieve returned error: %v", err)
+	}
+	if len(podDetailsList) != 1 {
+		t.Fatalf("expected 1 pod, got %d", len(podDetailsList))
+	}
+
+	pod := podDetailsList[0]
+	if pod.Name != "web-0" || pod.Namespace != "default" || pod.Phase != "Running" || pod.IP != "10.0.0.7" {
+		t.Errorf("unexpected pod details: %+v", pod)
+	}
+	if len(pod.Conditions) != 1 || pod.Conditions[0].Type != "Ready" || pod.Conditions[0].Status != "True" {
+		t.Errorf("unexpected conditions: %+v", pod.Conditions)
+	}
+	if len(pod.ContainerDetails) != 1 || pod.ContainerDetails[0].ContainerName != "web" {
+		t.Fatalf("unexpected containers: %+v", pod.ContainerDetails)
+	}
+	port := pod.ContainerDetails[0].Ports[0]
+	if port.PortName != "http" || port.Protocol != "TCP" || port.ContainerPort != 8080 || port.HostPort != 80 {
+		t.Errorf("unexpected port: %+v", port)
+	}
+}
+
+func TestPodDetailsRetrieveNotFound(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	_, err := PodDetailsRetrieve(clientset, "default", "missing")
+	if err == nil {
+		t.Fatal("expected an error for a missing pod")
+	}
+}
+
+func TestGetDeploymentDetails(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(3),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "web",
+						Ports: []corev1.ContainerPort{{Name: "http", Protocol: corev1.ProtocolTCP, ContainerPort: 8080}},
+					}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{AvailableReplicas: 2, ReadyReplicas: 2, UpdatedReplicas: 3},
+	})
+
+	deploymentDetailsList, err := GetDeploymentDetails(clientset, "default", "web")
+	if err != nil {
+		t.Fatalf("GetDeploymentDetails returned error: %v", err)
+	}
+	if len(deploymentDetailsList) != 1 {
+		t.Fatalf("expected 1 deployment, got %d", len(deploymentDetailsList))
+	}
+
+	deployment := deploymentDetailsList[0]
+	if deployment.Replicas != 3 || deployment.AvailableReplicas != 2 || deployment.ReadyReplicas != 2 || deployment.UpdatedReplicas != 3 {
+		t.Errorf("unexpected replica counts: %+v", deployment)
+	}
+	if deployment.Strategy != "RollingUpdate" {
+		t.Errorf("expected RollingUpdate strategy, got %q", deployment.Strategy)
+	}
+	if deployment.Selector != "app=web" {
+		t.Errorf("expected selector app=web, got %q", deployment.Selector)
+	}
+	if len(deployment.Containers) != 1 || deployment.Containers[0].Ports[0].ContainerPort != 8080 {
+		t.Errorf("unexpected containers: %+v", deployment.Containers)
+	}
+}
+
+func TestGetLabelSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		want     string
+	}{
+		{name: "nil", selector: nil, want: ""},
+		{name: "empty", selector: &metav1.LabelSelector{}, want: ""},
+		{name: "single label", selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, want: "app=web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getLabelSelector(tt.selector); got != tt.want {
+				t.Errorf("getLabelSelector() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameSpaceDetailsRetrieve(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "team-a",
+				Labels:      map[string]string{"team": "a"},
+				Annotations: map[string]string{"owner": "ops"},
+			},
+			Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		},
+		&corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "team-a"}},
+	)
+
+	nsDetailsList, err := NameSpaceDetailsRetrieve(clientset, "team-a")
+	if err != nil {
+		t.Fatalf("NameSpaceDetailsRetrieve returned error: %v", err)
+	}
+	if len(nsDetailsList) != 1 {
+		t.Fatalf("expected 1 namespace, got %d", len(nsDetailsList))
+	}
+
+	ns := nsDetailsList[0]
+	if ns.Name != "team-a" || ns.Status != "Active" || ns.ResourceQuota != "compute" {
+		t.Errorf("unexpected namespace details: %+v", ns)
+	}
+	if ns.Labels["team"] != "a" || ns.Annotations["owner"] != "ops" {
+		t.Errorf("unexpected labels or annotations: %+v", ns)
+	}
+}
+
+func TestNameSpaceDetailsRetrieveWithoutQuota(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}})
+
+	nsDetailsList, err := NameSpaceDetailsRetrieve(clientset, "team-b")
+	if err != nil {
+		t.Fatalf("NameSpaceDetailsRetrieve returned error: %v", err)
+	}
+	if nsDetailsList[0].ResourceQuota != "" {
+		t.Errorf("expected no resource quota, got %q", nsDetailsList[0].ResourceQuota)
+	}
+}
+
+func TestServiceDetailsRetrieve(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.ServiceSpec{
+			Type:            corev1.ServiceTypeNodePort,
+			ClusterIP:       "10.96.0.10",
+			ExternalIPs:     []string{"192.168.1.10"},
+			Selector:        map[string]string{"app": "web"},
+			SessionAffinity: corev1.ServiceAffinityNone,
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromString("http"),
+				NodePort:   30080,
+			}},
+		},
+	})
+
+	serviceDetailsList, err := ServiceDetailsRetrieve(clientset, "default", "web")
+	if err != nil {
+		t.Fatalf("ServiceDetailsRetrieve returned error: %v", err)
+	}
+	if len(serviceDetailsList) != 1 {
+		t.Fatalf("expected 1 service, got %d", len(serviceDetailsList))
+	}
+
+	service := serviceDetailsList[0]
+	if service.Type != "NodePort" || service.ClusterIP != "10.96.0.10" || service.SessionAffinity != "None" {
+		t.Errorf("unexpected service details: %+v", service)
+	}
+	if len(service.ExternalIPs) != 1 || service.ExternalIPs[0] != "192.168.1.10" {
+		t.Errorf("unexpected external IPs: %v", service.ExternalIPs)
+	}
+	port := service.Ports[0]
+	if port.Name != "http" || port.Port != 80 || port.TargetPort != "http" || port.NodePort != 30080 {
+		t.Errorf("unexpected port: %+v", port)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DryRunStrategy selects whether a change is only previewed.
+type DryRunStrategy string
+
+const (
+	// DryRunNone sends the change to the cluster.
+	DryRunNone DryRunStrategy = ""
+	// DryRunClient validates the change locally without sending it.
+	DryRunClient DryRunStrategy = "client"
+	// DryRunServer sends the change with DryRun: All, so the API server
+	// validates and admits it without persisting anything.
+	DryRunServer DryRunStrategy = "server"
+)
+
+// ParseDryRun parses the value of a --dry-run flag; "none" and "" disable
+// dry run.
+func ParseDryRun(value string) (DryRunStrategy, error) {
+	switch value {
+	case "", "none":
+		return DryRunNone, nil
+	case string(DryRunClient), string(DryRunServer):
+		return DryRunStrategy(value), nil
+	default:
+		return DryRunNone, fmt.Errorf("invalid dry run %q, expected one of none, client or server", value)
+	}
+}
+
+// serverDryRun is the DryRun option sent with requests.
+func (d DryRunStrategy) serverDryRun() []string {
+	if d == DryRunServer {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
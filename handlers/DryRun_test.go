@@ -0,0 +1,39 @@
+package handlers
+
+import "testing"
+
+func TestParseDryRun(t *testing.T) {
+	tests := []struct {
+		value string
+		want  DryRunStrategy
+	}{
+		{"", DryRunNone},
+		{"none", DryRunNone},
+		{"client", DryRunClient},
+		{"server", DryRunServer},
+	}
+	for _, tt := range tests {
+		got, err := ParseDryRun(tt.value)
+		if err != nil {
+			t.Errorf("ParseDryRun(%q) returned error: %v", tt.value, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseDryRun(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := ParseDryRun("true"); err == nil {
+		t.Error("expected an error for an unknown dry run strategy")
+	}
+}
+
+func TestServerDryRun(t *testing.T) {
+	if got := DryRunServer.serverDryRun(); len(got) != 1 || got[0] != "All" {
+		t.Errorf("expected DryRun All for a server dry run, got %v", got)
+	}
+	for _, strategy := range []DryRunStrategy{DryRunNone, DryRunClient} {
+		if got := strategy.serverDryRun(); got != nil {
+			t.Errorf("expected no DryRun option for %q, got %v", strategy, got)
+		}
+	}
+}
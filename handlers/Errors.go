@@ -0,0 +1,22 @@
+package handlers
+
+import "errors"
+
+// Errors returned by handlers for failures that are not API errors. They
+// are wrapped with details, so check them with errors.Is. Failures reported
+// by the API server (not found, forbidden, conflict, ...) are returned as
+// *errors.StatusError from k8s.io/apimachinery/pkg/api/errors.
+var (
+	// ErrUnsupportedKind is returned for kinds the cluster does not serve.
+	ErrUnsupportedKind = errors.New("unsupported kind")
+	// ErrInvalidManifest is returned for manifests that cannot be decoded.
+	ErrInvalidManifest = errors.New("invalid manifest")
+	// ErrWaitTimeout is returned when an object is not ready in time.
+	ErrWaitTimeout = errors.New("timed out waiting")
+	// ErrNotReady is returned for objects that can no longer become
+	// ready, such as failed jobs.
+	ErrNotReady = errors.New("not ready")
+	// ErrAutoscaled is returned when scaling an object whose replicas are
+	// managed by a HorizontalPodAutoscaler.
+	ErrAutoscaled = errors.New("autoscaled")
+)
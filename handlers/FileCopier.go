@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// CopyProgress reports the files and bytes copied so far. It is reported
+// as the bytes of a file stream, and once more when the file is done.
+type CopyProgress struct {
+	// File is the path, inside the copied tree, of the file being copied.
+	File string
+	// Files counts the files copied completely.
+	Files int
+	Bytes int64
+	// TotalBytes is the size of everything being copied, 0 when unknown,
+	// as for copies from a pod.
+	TotalBytes int64
+}
+
+// CopyToPod copies a local file or directory to remotePath in a container,
+// streaming a tar archive to "tar -xf -" over exec. A remotePath ending in
+// "/" is a directory to copy into. File modes are kept.
+func CopyToPod(ctx context.Context, config *rest.Config, namespace string, podName string, container string, localPath string, remotePath string, progress func(CopyProgress)) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	total, err := treeSize(localPath)
+	if err != nil {
+		return err
+	}
+
+	remoteDir, remoteName := path.Split(remotePath)
+	if remoteName == "" {
+		remoteName = info.Name()
+	}
+	remoteDir = path.Clean(remoteDir)
+
+	reader, writer := io.Pipe()
+	writeDone := make(chan error, 1)
+	go func() {
+		err := writeTar(writer, localPath, remoteName, total, progress)
+		writer.CloseWithError(err)
+		writeDone <- err
+	}()
+
+	var stderr lockedBuffer
+	err = ExecInPod(ctx, config, namespace, podName, ExecOptions{
+		Container: container,
+		Command:   []string{"tar", "-xf", "-", "-C", remoteDir},
+		Stdin:     reader,
+		Stdout:    io.Discard,
+		Stderr:    &stderr,
+	})
+	// tar may stop reading before the padding at the end of the archive.
+	reader.Close()
+	writeErr := <-writeDone
+	if err != nil {
+		return remoteCopyError(err, stderr.String())
+	}
+	if errors.Is(writeErr, io.ErrClosedPipe) {
+		return nil
+	}
+	return writeErr
+}
+
+// CopyFromPod copies remotePath from a container to localPath, or into it
+// when localPath is a directory, reading a tar archive of it from
+// "tar -cf -" over exec. File modes are kept; entries that are links or that
+// would land outside localPath are skipped.
+func CopyFromPod(ctx context.Context, config *rest.Config, namespace string, podName string, container string, remotePath string, localPath string, progress func(CopyProgress)) error {
+	remotePath = path.Clean(remotePath)
+	remoteDir, remoteName := path.Split(remotePath)
+	if remoteName == "" || remoteName == "/" {
+		return fmt.Errorf("cannot copy %s, give a file or directory below it", remotePath)
+	}
+	if remoteDir == "" {
+		remoteDir = "."
+	}
+	if info, err := os.Stat(localPath); err == nil && info.IsDir() {
+		localPath = filepath.Join(localPath, remoteName)
+	}
+
+	reader, writer := io.Pipe()
+	var stderr lockedBuffer
+	execDone := make(chan error, 1)
+	go func() {
+		err := ExecInPod(ctx, config, namespace, podName, ExecOptions{
+			Container: container,
+			Command:   []string{"tar", "-cf", "-", "-C", remoteDir, remoteName},
+			Stdout:    writer,
+			Stderr:    &stderr,
+		})
+		writer.CloseWithError(err)
+		execDone <- err
+	}()
+
+	readErr := readTar(reader, remoteName, localPath, progress)
+	if readErr == nil {
+		// Read the padding after the end of the archive.
+		_, readErr = io.Copy(io.Discard, reader)
+	}
+	reader.CloseWithError(readErr)
+	if err := remoteCopyError(<-execDone, stderr.String()); err != nil {
+		return err
+	}
+	return readErr
+}
+
+// writeTar archives the tree at localPath, naming its root name.
+func writeTar(w io.Writer, localPath string, name string, total int64, progress func(CopyProgress)) error {
+	archive := tar.NewWriter(w)
+	current := CopyProgress{TotalBytes: total}
+	err := filepath.WalkDir(localPath, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		relative, err := filepath.Rel(localPath, file)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(name, filepath.ToSlash(relative))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		source, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		current.File = header.Name
+		if _, err := io.Copy(archive, newProgressReader(source, &current, progress)); err != nil {
+			return err
+		}
+		current.Files++
+		progress(current)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// readTar extracts an archive whose root is name into localPath.
+func readTar(r io.Reader, name string, localPath string, progress func(CopyProgress)) error {
+	archive := tar.NewReader(r)
+	current := CopyProgress{}
+	root := filepath.Clean(localPath)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		relative, ok := strings.CutPrefix(path.Clean(header.Name), name)
+		if !ok || (relative != "" && !strings.HasPrefix(relative, "/")) {
+			continue
+		}
+		target := filepath.Join(root, filepath.FromSlash(relative))
+		if target != root && !strings.HasPrefix(target, root+string(filepath.Separator)) {
+			continue
+		}
+
+		mode := header.FileInfo().Mode().Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0o700); err != nil {
+				return err
+			}
+			if err := os.Chmod(target, mode|0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			current.File = header.Name
+			if err := writeFile(target, newProgressReader(archive, &current, progress), mode); err != nil {
+				return err
+			}
+			current.Files++
+			progress(current)
+		}
+	}
+}
+
+func writeFile(target string, r io.Reader, mode fs.FileMode) error {
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	// OpenFile applies the umask and keeps the mode of existing files.
+	return os.Chmod(target, mode)
+}
+
+// progressReader adds the bytes read from r to current and reports it.
+type progressReader struct {
+	r        io.Reader
+	current  *CopyProgress
+	progress func(CopyProgress)
+}
+
+func newProgressReader(r io.Reader, current *CopyProgress, progress func(CopyProgress)) io.Reader {
+	return &progressReader{r: r, current: current, progress: progress}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.current.Bytes += int64(n)
+		p.progress(*p.current)
+	}
+	return n, err
+}
+
+// treeSize is the size of the regular files under localPath.
+func treeSize(localPath string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(localPath, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// remoteCopyError adds what tar printed in the container to err.
+func remoteCopyError(err error, stderr string) error {
+	if err == nil {
+		return nil
+	}
+	if message := strings.TrimSpace(stderr); message != "" {
+		return fmt.Errorf("%w: %s", err, message)
+	}
+	return err
+}
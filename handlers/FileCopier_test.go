@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// tarExecutor plays the tar of a container: it extracts what it receives
+// on stdin into files and answers "tar -cf" with archive.
+type tarExecutor struct {
+	url     *url.URL
+	archive []byte
+	files   map[string]tarEntry
+	command string
+}
+
+type tarEntry struct {
+	mode    int64
+	content string
+}
+
+func (e *tarExecutor) Stream(options remotecommand.StreamOptions) error {
+	return e.StreamWithContext(context.Background(), options)
+}
+
+func (e *tarExecutor) StreamWithContext(ctx context.Context, options remotecommand.StreamOptions) error {
+	e.command = strings.Join(e.url.Query()["command"], " ")
+	if options.Stdin == nil {
+		_, err := options.Stdout.Write(e.archive)
+		return err
+	}
+	archive := tar.NewReader(options.Stdin)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		content, _ := io.ReadAll(archive)
+		e.files[header.Name] = tarEntry{mode: header.Mode, content: string(content)}
+	}
+}
+
+func useTarExecutor(t *testing.T, archive []byte) *tarExecutor {
+	executor := &tarExecutor{archive: archive, files: map[string]tarEntry{}}
+	original := newExecutor
+	newExecutor = func(config *rest.Config, execURL *url.URL) (remotecommand.Executor, error) {
+		executor.url = execURL
+		return executor, nil
+	}
+	t.Cleanup(func() { newExecutor = original })
+	return executor
+}
+
+func TestCopyToPod(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "conf", "extra"), 0o755)
+	os.WriteFile(filepath.Join(dir, "conf", "app.yaml"), []byte("debug: true"), 0o644)
+	os.WriteFile(filepath.Join(dir, "conf", "extra", "run.sh"), []byte("#!/bin/sh"), 0o755)
+	executor := useTarExecutor(t, nil)
+
+	var last CopyProgress
+	config := &rest.Config{Host: "https://cluster.example"}
+	err := CopyToPod(context.TODO(), config, "default", "web", "app", filepath.Join(dir, "conf"), "/etc/debug", func(progress CopyProgress) { last = progress })
+	if err != nil {
+		t.Fatalf("CopyToPod returned error: %v", err)
+	}
+	if executor.command != "tar -xf - -C /etc" {
+		t.Errorf("unexpected remote command %q", executor.command)
+	}
+	if entry := executor.files["debug/app.yaml"]; entry.content != "debug: true" || entry.mode != 0o644 {
+		t.Errorf("unexpected app.yaml entry %+v in %v", entry, executor.files)
+	}
+	if entry := executor.files["debug/extra/run.sh"]; entry.mode != 0o755 {
+		t.Errorf("expected run.sh to stay executable, got %o", entry.mode)
+	}
+	if last.Files != 2 || last.Bytes != last.TotalBytes || last.TotalBytes != 20 {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+
+	// Progress is reported while a large file streams, before it is done.
+	os.WriteFile(filepath.Join(dir, "large.bin"), make([]byte, 256*1024), 0o644)
+	var partial bool
+	err = CopyToPod(context.TODO(), config, "default", "web", "", filepath.Join(dir, "large.bin"), "/tmp/", func(progress CopyProgress) {
+		partial = partial || progress.Files == 0 && progress.Bytes > 0 && progress.Bytes < progress.TotalBytes
+	})
+	if err != nil || !partial {
+		t.Errorf("expected progress while large.bin streams, got %v", err)
+	}
+
+	err = CopyToPod(context.TODO(), config, "default", "web", "", filepath.Join(dir, "conf", "app.yaml"), "/tmp/", func(CopyProgress) {})
+	if err != nil {
+		t.Fatalf("CopyToPod returned error: %v", err)
+	}
+	if _, found := executor.files["app.yaml"]; !found || executor.command != "tar -xf - -C /tmp" {
+		t.Errorf("expected app.yaml to be copied into /tmp, got %q and %v", executor.command, executor.files)
+	}
+}
+
+func TestCopyFromPod(t *testing.T) {
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	entries := []struct {
+		header  tar.Header
+		content string
+	}{
+		{tar.Header{Name: "dumps/", Typeflag: tar.TypeDir, Mode: 0o755}, ""},
+		{tar.Header{Name: "dumps/heap.hprof", Typeflag: tar.TypeReg, Mode: 0o600}, "heap"},
+		{tar.Header{Name: "dumps/../../escape", Typeflag: tar.TypeReg, Mode: 0o644}, "nope"},
+		{tar.Header{Name: "dumps/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}, ""},
+	}
+	for _, entry := range entries {
+		entry.header.Size = int64(len(entry.content))
+		writer.WriteHeader(&entry.header)
+		writer.Write([]byte(entry.content))
+	}
+	writer.Close()
+	executor := useTarExecutor(t, archive.Bytes())
+
+	dir := t.TempDir()
+	files := 0
+	config := &rest.Config{Host: "https://cluster.example"}
+	if err := CopyFromPod(context.TODO(), config, "default", "web", "", "/var/dumps", dir, func(progress CopyProgress) { files = progress.Files }); err != nil {
+		t.Fatalf("CopyFromPod returned error: %v", err)
+	}
+	if executor.command != "tar -cf - -C /var/ dumps" {
+		t.Errorf("unexpected remote command %q", executor.command)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "dumps", "heap.hprof"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected heap.hprof with mode 0600, got %v, %v", info, err)
+	}
+	if files != 1 {
+		t.Errorf("expected 1 file copied, got %d", files)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "dumps", "link")); !os.IsNotExist(err) {
+		t.Errorf("expected the symlink to be skipped, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape")); !os.IsNotExist(err) {
+		t.Errorf("expected the escaping entry to be skipped, got %v", err)
+	}
+}
@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ContainerImage names the image to set for one container.
+type ContainerImage struct {
+	Container string `json:"container"`
+	Image     string `json:"image"`
+}
+
+// ManifestImageResult is the outcome of setting images in a manifest file.
+type ManifestImageResult struct {
+	File string `json:"file"`
+	// Changed counts the image fields rewritten in the file.
+	Changed int `json:"changed"`
+}
+
+// WorkloadContainers returns the containers of the pod template of a
+// deployment, statefulset or daemonset, init containers included.
+func WorkloadContainers(clientset kubernetes.Interface, kind string, name string, namespace string) ([]ContainerDetails, error) {
+	template, err := workloadTemplate(clientset, kind, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var containers []ContainerDetails
+	for _, list := range [][]corev1.Container{template.Spec.InitContainers, template.Spec.Containers} {
+		for _, container := range list {
+			containers = append(containers, ContainerDetails{ContainerName: container.Name})
+		}
+	}
+	return containers, nil
+}
+
+// SetImages sets container images in the pod template of a deployment,
+// statefulset or daemonset and records changeCause in the
+// ChangeCauseAnnotation, which deployments copy to the ReplicaSet of the
+// new revision so that it shows in the rollout history.
+func SetImages(clientset kubernetes.Interface, kind string, name string, namespace string, images []ContainerImage, changeCause string) error {
+	template, err := workloadTemplate(clientset, kind, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	var containers, initContainers []ContainerImage
+	for _, image := range images {
+		switch {
+		case templateHasContainer(template.Spec.Containers, image.Container):
+			containers = append(containers, image)
+		case templateHasContainer(template.Spec.InitContainers, image.Container):
+			initContainers = append(initContainers, image)
+		default:
+			return fmt.Errorf("%s %s has no container %q", strings.ToLower(kind), name, image.Container)
+		}
+	}
+
+	podSpec := map[string]interface{}{}
+	if len(containers) > 0 {
+		podSpec["containers"] = containerImagePatch(containers)
+	}
+	if len(initContainers) > 0 {
+		podSpec["initContainers"] = containerImagePatch(initContainers)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{ChangeCauseAnnotation: changeCause},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{"spec": podSpec},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return patchWorkload(clientset, kind, name, namespace, patch)
+}
+
+func templateHasContainer(containers []corev1.Container, name string) bool {
+	for _, container := range containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// containerImagePatch lists containers by their merge key, name, so that
+// a strategic merge patch only changes their image.
+func containerImagePatch(images []ContainerImage) []map[string]string {
+	var patch []map[string]string
+	for _, image := range images {
+		patch = append(patch, map[string]string{"name": image.Container, "image": image.Image})
+	}
+	return patch
+}
+
+// imageField is a container found in a manifest, with the node of its
+// image field, nil when the container has none.
+type imageField struct {
+	container string
+	image     *yaml.Node
+}
+
+// ManifestContainers returns the containers of the pod templates and pod
+// specs in the manifests. With a kind, only the containers of the object of
+// that kind and name are returned.
+func ManifestContainers(manifests ManifestOptions, kind string, name string) ([]ContainerDetails, error) {
+	files, err := rewritableManifestFiles(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []ContainerDetails
+	seen := map[string]bool{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := manifestImageFields(content, kind, name)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %v", ErrInvalidManifest, file, err)
+		}
+		for _, field := range fields {
+			if !seen[field.container] {
+				seen[field.container] = true
+				containers = append(containers, ContainerDetails{ContainerName: field.container})
+			}
+		}
+	}
+	return containers, nil
+}
+
+// SetManifestImages rewrites the image of the named containers in the
+// manifest files in place. Only the image values are replaced in the text
+// of the files, so comments, quoting and formatting are kept. With a kind,
+// only the object of that kind and name is changed.
+func SetManifestImages(manifests ManifestOptions, kind string, name string, images []ContainerImage) ([]ManifestImageResult, error) {
+	files, err := rewritableManifestFiles(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ManifestImageResult
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return results, err
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return results, err
+		}
+		rewritten, changed, err := setManifestImages(content, kind, name, images)
+		if err != nil {
+			return results, fmt.Errorf("%w %s: %v", ErrInvalidManifest, file, err)
+		}
+		if changed > 0 {
+			if err := os.WriteFile(file, rewritten, info.Mode().Perm()); err != nil {
+				return results, err
+			}
+		}
+		results = append(results, ManifestImageResult{File: file, Changed: changed})
+	}
+	return results, nil
+}
+
+// rewritableManifestFiles expands the manifest paths into files; stdin
+// cannot be rewritten in place.
+func rewritableManifestFiles(manifests ManifestOptions) ([]string, error) {
+	if len(manifests.Paths) == 0 {
+		return nil, fmt.Errorf("no manifest paths given")
+	}
+	var files []string
+	for _, path := range manifests.Paths {
+		if path == StdinPath {
+			return nil, fmt.Errorf("manifests read from stdin cannot be rewritten in place")
+		}
+		pathFiles, err := manifestFiles(path, manifests.Recursive)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, pathFiles...)
+	}
+	return files, nil
+}
+
+// setManifestImages returns content with the images replaced and the
+// number of image values that changed.
+func setManifestImages(content []byte, kind string, name string, images []ContainerImage) ([]byte, int, error) {
+	fields, err := manifestImageFields(content, kind, name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	type replacement struct {
+		start, end int
+		text       string
+	}
+	var replacements []replacement
+	for _, field := range fields {
+		for _, image := range images {
+			if image.Container != field.container {
+				continue
+			}
+			if field.image == nil {
+				return nil, 0, fmt.Errorf("container %q has no image field", field.container)
+			}
+			if field.image.Value == image.Image {
+				continue
+			}
+			start, end, err := scalarExtent(content, field.image)
+			if err != nil {
+				return nil, 0, fmt.Errorf("container %q: %v", field.container, err)
+			}
+			replacements = append(replacements, replacement{start, end, quoteScalar(image.Image, field.image.Style)})
+		}
+	}
+
+	// Replace from the end, so that the offsets of earlier values hold.
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].start > replacements[j].start })
+	rewritten := append([]byte(nil), content...)
+	for _, r := range replacements {
+		rewritten = append(rewritten[:r.start], append([]byte(r.text), rewritten[r.end:]...)...)
+	}
+	return rewritten, len(replacements), nil
+}
+
+// manifestImageFields finds the containers, and init containers, in every
+// document of content.
+func manifestImageFields(content []byte, kind string, name string) ([]imageField, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	var fields []imageField
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return fields, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		collectImageFields(&document, kind, name, kind == "", &fields)
+	}
+}
+
+// collectImageFields walks node. Containers count when selected, which
+// every object in the walk sets by matching it against kind and name.
+func collectImageFields(node *yaml.Node, kind string, name string, selected bool, fields *[]imageField) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			collectImageFields(child, kind, name, selected, fields)
+		}
+	case yaml.MappingNode:
+		objectKind := mappingValue(node, "kind")
+		metadata := mappingValue(node, "metadata")
+		if kind != "" && objectKind != nil && metadata != nil {
+			objectName := mappingValue(metadata, "name")
+			selected = kindMatches(kind, objectKind.Value) && objectName != nil && objectName.Value == name
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if (key.Value == "containers" || key.Value == "initContainers") && value.Kind == yaml.SequenceNode {
+				if selected {
+					*fields = append(*fields, containerImageFields(value)...)
+				}
+				continue
+			}
+			collectImageFields(value, kind, name, selected, fields)
+		}
+	}
+}
+
+func containerImageFields(containers *yaml.Node) []imageField {
+	var fields []imageField
+	for _, container := range containers.Content {
+		if container.Kind != yaml.MappingNode {
+			continue
+		}
+		name := mappingValue(container, "name")
+		if name == nil {
+			continue
+		}
+		field := imageField{container: name.Value}
+		if image := mappingValue(container, "image"); image != nil && image.Kind == yaml.ScalarNode {
+			field.image = image
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// kindMatches compares a kind given by the user, which may be a short name
+// such as "deploy", with the kind of a manifest object.
+func kindMatches(kind string, objectKind string) bool {
+	if strings.EqualFold(kind, objectKind) {
+		return true
+	}
+	rolloutKind, err := ParseRolloutKind(kind)
+	return err == nil && rolloutKind == objectKind
+}
+
+// scalarExtent returns the byte offsets of the text of a single line
+// scalar in content, quotes included.
+func scalarExtent(content []byte, node *yaml.Node) (int, int, error) {
+	start := 0
+	for line := 1; line < node.Line; line++ {
+		next := bytes.IndexByte(content[start:], '\n')
+		if next < 0 {
+			return 0, 0, fmt.Errorf("line %d is out of range", node.Line)
+		}
+		start += next + 1
+	}
+	// Columns count characters, not bytes.
+	for column := 1; column < node.Column && start < len(content); column++ {
+		_, size := utf8.DecodeRune(content[start:])
+		start += size
+	}
+
+	rest := content[start:]
+	switch node.Style {
+	case 0, yaml.TaggedStyle:
+		if !bytes.HasPrefix(rest, []byte(node.Value)) {
+			return 0, 0, fmt.Errorf("cannot find the image %q at line %d", node.Value, node.Line)
+		}
+		return start, start + len(node.Value), nil
+	case yaml.DoubleQuotedStyle:
+		for i := 1; i < len(rest) && rest[i] != '\n'; i++ {
+			switch rest[i] {
+			case '\\':
+				i++
+			case '"':
+				return start, start + i + 1, nil
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := 1; i < len(rest) && rest[i] != '\n'; i++ {
+			if rest[i] != '\'' {
+				continue
+			}
+			if i+1 < len(rest) && rest[i+1] == '\'' {
+				i++
+				continue
+			}
+			return start, start + i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("the image at line %d is not a single line scalar", node.Line)
+}
+
+// quoteScalar writes value in the quoting style of the value it replaces.
+func quoteScalar(value string, style yaml.Style) string {
+	switch style {
+	case yaml.DoubleQuotedStyle:
+		return strconv.Quote(value)
+	case yaml.SingleQuotedStyle:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return value
+}
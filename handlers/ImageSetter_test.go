@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const imageManifest = `# The web tier.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web   # public
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: "web:1"   # same image as the app
+      containers:
+        - name: app
+          image: web:1 # bumped by CI
+        - {name: sidecar, image: 'proxy:1'}
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: app
+              image: web:1
+`
+
+func TestSetManifestImages(t *testing.T) {
+	images := []ContainerImage{{"app", "web:2"}, {"migrate", "web:2"}, {"sidecar", "proxy:2"}}
+	rewritten, changed, err := setManifestImages([]byte(imageManifest), "", "", images)
+	if err != nil {
+		t.Fatalf("setManifestImages returned error: %v", err)
+	}
+	expected := `# The web tier.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web   # public
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: "web:2"   # same image as the app
+      containers:
+        - name: app
+          image: web:2 # bumped by CI
+        - {name: sidecar, image: 'proxy:2'}
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: app
+              image: web:2
+`
+	if changed != 4 || string(rewritten) != expected {
+		t.Errorf("expected 4 changes, got %d:\n%s", changed, rewritten)
+	}
+
+	rewritten, changed, err = setManifestImages([]byte(imageManifest), "deploy", "web", []ContainerImage{{"app", "web:3"}})
+	if err != nil {
+		t.Fatalf("setManifestImages returned error: %v", err)
+	}
+	if changed != 1 || !strings.HasSuffix(string(rewritten), "image: web:1\n") {
+		t.Errorf("expected only the deployment to change, got %d changes:\n%s", changed, rewritten)
+	}
+
+	if _, changed, _ := setManifestImages(rewritten, "deploy", "web", []ContainerImage{{"app", "web:3"}}); changed != 0 {
+		t.Errorf("expected setting the same image to change nothing, got %d", changed)
+	}
+}
+
+func TestManifestContainersAndFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "web.yaml")
+	os.WriteFile(file, []byte(imageManifest), 0o640)
+	manifests := ManifestOptions{Paths: []string{dir}}
+
+	containers, err := ManifestContainers(manifests, "cronjob", "report")
+	if err != nil {
+		t.Fatalf("ManifestContainers returned error: %v", err)
+	}
+	if len(containers) != 1 || containers[0].ContainerName != "app" {
+		t.Errorf("expected the container of the cronjob, got %+v", containers)
+	}
+	if containers, _ := ManifestContainers(manifests, "", ""); len(containers) != 3 {
+		t.Errorf("expected migrate, app and sidecar, got %+v", containers)
+	}
+
+	results, err := SetManifestImages(manifests, "", "", []ContainerImage{{"sidecar", "proxy:2"}})
+	if err != nil {
+		t.Fatalf("SetManifestImages returned error: %v", err)
+	}
+	if len(results) != 1 || results[0].Changed != 1 {
+		t.Errorf("unexpected results %+v", results)
+	}
+	info, _ := os.Stat(file)
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("expected the file mode to be kept, got %o", info.Mode().Perm())
+	}
+
+	if _, err := SetManifestImages(ManifestOptions{Paths: []string{StdinPath}}, "", "", nil); err == nil {
+		t.Error("expected an error for stdin")
+	}
+}
+
+func TestSetImages(t *testing.T) {
+	deployment := newRolloutDeployment("web:1")
+	deployment.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "migrate", Image: "web:1"}}
+	deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, corev1.Container{Name: "sidecar", Image: "proxy:1"})
+	clientset := fake.NewSimpleClientset(deployment)
+
+	containers, err := WorkloadContainers(clientset, RolloutDeployment, "web", "default")
+	if err != nil || len(containers) != 3 || containers[0].ContainerName != "migrate" {
+		t.Fatalf("unexpected containers %+v, %v", containers, err)
+	}
+
+	images := []ContainerImage{{"app", "web:2"}, {"migrate", "web:2"}}
+	if err := SetImages(clientset, RolloutDeployment, "web", "default", images, "kuba set image deployment/web app=web:2 migrate=web:2"); err != nil {
+		t.Fatalf("SetImages returned error: %v", err)
+	}
+	updated, _ := clientset.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	spec := updated.Spec.Template.Spec
+	if spec.Containers[0].Image != "web:2" || spec.Containers[1].Image != "proxy:1" || spec.InitContainers[0].Image != "web:2" {
+		t.Errorf("unexpected images after set: %+v", spec)
+	}
+	if cause := updated.Annotations[ChangeCauseAnnotation]; cause != "kuba set image deployment/web app=web:2 migrate=web:2" {
+		t.Errorf("expected the change-cause to be recorded, got %q", cause)
+	}
+
+	if err := SetImages(clientset, RolloutDeployment, "web", "default", []ContainerImage{{"db", "postgres:16"}}, ""); err == nil {
+		t.Error("expected an error for an unknown container")
+	}
+	if err := SetImages(fake.NewSimpleClientset(&appsv1.DaemonSet{}), RolloutDaemonSet, "agent", "default", images, ""); err == nil {
+		t.Error("expected an error for a missing daemonset")
+	}
+}
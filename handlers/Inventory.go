@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Labels and data key of the inventory config maps.
+const (
+	InventoryLabel     = "kuba.io/inventory"
+	ManagedByLabel     = "app.kubernetes.io/managed-by"
+	inventoryObjectKey = "objects"
+)
+
+var inventoryResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// InventoryObject identifies an object recorded in an inventory.
+type InventoryObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+func (o InventoryObject) String() string {
+	if o.Namespace == "" {
+		return fmt.Sprintf("%s/%s", o.Kind, o.Name)
+	}
+	return fmt.Sprintf("%s/%s in %s", o.Kind, o.Name, o.Namespace)
+}
+
+// identity is what makes two entries the same object: the API version is
+// left out, since moving a manifest to another version of its kind (eg:
+// autoscaling/v2beta2 to autoscaling/v2) keeps the same live object.
+func (o InventoryObject) identity() InventoryObject {
+	gv, err := schema.ParseGroupVersion(o.APIVersion)
+	if err != nil {
+		return o
+	}
+	return InventoryObject{APIVersion: gv.Group, Kind: o.Kind, Namespace: o.Namespace, Name: o.Name}
+}
+
+func inventoryObjectOf(obj *unstructured.Unstructured) InventoryObject {
+	return InventoryObject{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// InventoryName is the name of the config map holding the inventory of app.
+func InventoryName(app string) string {
+	return "kuba-inventory-" + app
+}
+
+// InventoryFromCreate lists the manifest objects that exist after a create,
+// including the ones that already existed. complete is false when other
+// failures mean the list may miss objects of the manifest set.
+func InventoryFromCreate(results []CreateResult) (objects []InventoryObject, complete bool) {
+	complete = true
+	for _, result := range results {
+		switch {
+		case result.Err == nil, apierrors.IsAlreadyExists(result.Err):
+			objects = append(objects, inventoryObjectOf(result.Object))
+		default:
+			complete = false
+		}
+	}
+	return objects, complete
+}
+
+// InventoryFromApply lists the objects applied successfully. complete is
+// false when some objects failed to apply.
+func InventoryFromApply(results []ApplyResult) (objects []InventoryObject, complete bool) {
+	complete = true
+	for _, result := range results {
+		if result.Err != nil {
+			complete = false
+			continue
+		}
+		objects = append(objects, inventoryObjectOf(result.Object))
+	}
+	return objects, complete
+}
+
+// ReadInventory returns the objects recorded for app in namespace. An app
+// without an inventory has no objects.
+func ReadInventory(dynamicClient dynamic.Interface, namespace string, app string) ([]InventoryObject, error) {
+	inventory, err := dynamicClient.Resource(inventoryResource).Namespace(inventoryNamespace(namespace)).Get(context.TODO(), InventoryName(app), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, _, _ := unstructured.NestedString(inventory.Object, "data", inventoryObjectKey)
+	if content == "" {
+		return nil, nil
+	}
+	var objects []InventoryObject
+	if err := json.Unmarshal([]byte(content), &objects); err != nil {
+		return nil, fmt.Errorf("reading inventory %s: %w", InventoryName(app), err)
+	}
+	return objects, nil
+}
+
+// WriteInventory records objects as the inventory of app in namespace,
+// creating the labelled config map on first use.
+func WriteInventory(dynamicClient dynamic.Interface, namespace string, app string, objects []InventoryObject, dryRun DryRunStrategy) error {
+	if dryRun == DryRunClient {
+		return nil
+	}
+	objects = sortedInventory(objects)
+	content, err := json.Marshal(objects)
+	if err != nil {
+		return err
+	}
+
+	namespace = inventoryNamespace(namespace)
+	inventory := &unstructured.Unstructured{}
+	inventory.SetAPIVersion("v1")
+	inventory.SetKind("ConfigMap")
+	inventory.SetName(InventoryName(app))
+	inventory.SetNamespace(namespace)
+	inventory.SetLabels(map[string]string{InventoryLabel: app, ManagedByLabel: FieldManager})
+	if err := unstructured.SetNestedField(inventory.Object, string(content), "data", inventoryObjectKey); err != nil {
+		return err
+	}
+
+	client := dynamicClient.Resource(inventoryResource).Namespace(namespace)
+	existing, err := client.Get(context.TODO(), inventory.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(context.TODO(), inventory, metav1.CreateOptions{DryRun: dryRun.serverDryRun()})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	inventory.SetResourceVersion(existing.GetResourceVersion())
+	_, err = client.Update(context.TODO(), inventory, metav1.UpdateOptions{DryRun: dryRun.serverDryRun()})
+	return err
+}
+
+// MergeInventory returns the objects of both inventories without duplicates.
+func MergeInventory(previous []InventoryObject, current []InventoryObject) []InventoryObject {
+	merged := append([]InventoryObject{}, current...)
+	merged = append(merged, PruneCandidates(previous, current)...)
+	return sortedInventory(merged)
+}
+
+// PruneCandidates returns the previously recorded objects that are not
+// part of the current manifest set, comparing objects by group, kind,
+// namespace and name.
+func PruneCandidates(previous []InventoryObject, current []InventoryObject) []InventoryObject {
+	keep := map[InventoryObject]bool{}
+	for _, obj := range current {
+		keep[obj.identity()] = true
+	}
+
+	var prune []InventoryObject
+	for _, obj := range previous {
+		if !keep[obj.identity()] {
+			prune = append(prune, obj)
+		}
+	}
+	return prune
+}
+
+// PruneObjects deletes objects in the reverse of the creation order, so
+// workloads go before the configuration and namespaces they use. Objects
+// that are already gone count as pruned.
+func PruneObjects(dynamicClient dynamic.Interface, mapper meta.RESTMapper, objects []InventoryObject, dryRun DryRunStrategy) []DeleteResult {
+	objects = sortedInventory(objects)
+	sort.SliceStable(objects, func(i, j int) bool {
+		return kindPriority(objects[i].Kind) > kindPriority(objects[j].Kind)
+	})
+
+	var results []DeleteResult
+	for _, obj := range objects {
+		result := DeleteResult{Kind: obj.Kind, Name: obj.Name, Namespace: obj.Namespace}
+		result.Err = pruneObject(dynamicClient, mapper, obj, dryRun)
+		if apierrors.IsNotFound(result.Err) {
+			result.Err = nil
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func pruneObject(dynamicClient dynamic.Interface, mapper meta.RESTMapper, obj InventoryObject, dryRun DryRunStrategy) error {
+	gv, err := schema.ParseGroupVersion(obj.APIVersion)
+	if err != nil {
+		return err
+	}
+	mapping, err := mappingFor(mapper, gv.WithKind(obj.Kind))
+	if err != nil {
+		return err
+	}
+
+	resource := resourceInterface(dynamicClient, mapping, obj.Namespace)
+	if dryRun == DryRunClient {
+		_, err := resource.Get(context.TODO(), obj.Name, metav1.GetOptions{})
+		return err
+	}
+	propagation := metav1.DeletePropagationBackground
+	return resource.Delete(context.TODO(), obj.Name, metav1.DeleteOptions{PropagationPolicy: &propagation, DryRun: dryRun.serverDryRun()})
+}
+
+func inventoryNamespace(namespace string) string {
+	if namespace == "" {
+		return corev1.NamespaceDefault
+	}
+	return namespace
+}
+
+func sortedInventory(objects []InventoryObject) []InventoryObject {
+	sorted := append([]InventoryObject{}, objects...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return sorted
+}
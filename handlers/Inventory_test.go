@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInventoryRoundTrip(t *testing.T) {
+	dynamicClient := newFakeDynamicClient()
+
+	objects, err := ReadInventory(dynamicClient, "team-a", "shop")
+	if err != nil || objects != nil {
+		t.Fatalf("expected an empty inventory before the first write, got %v, %v", objects, err)
+	}
+
+	first := []InventoryObject{
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "team-a", Name: "web"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "team-a"},
+	}
+	if err := WriteInventory(dynamicClient, "team-a", "shop", first, DryRunNone); err != nil {
+		t.Fatalf("WriteInventory returned error: %v", err)
+	}
+	second := first[:1]
+	if err := WriteInventory(dynamicClient, "team-a", "shop", second, DryRunNone); err != nil {
+		t.Fatalf("updating the inventory returned error: %v", err)
+	}
+
+	objects, err = ReadInventory(dynamicClient, "team-a", "shop")
+	if err != nil {
+		t.Fatalf("ReadInventory returned error: %v", err)
+	}
+	if len(objects) != 1 || objects[0] != second[0] {
+		t.Errorf("expected the updated inventory, got %v", objects)
+	}
+
+	inventory, err := dynamicClient.Resource(configMapsGVR).Namespace("team-a").Get(context.TODO(), "kuba-inventory-shop", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting the inventory config map: %v", err)
+	}
+	if labels := inventory.GetLabels(); labels[InventoryLabel] != "shop" || labels[ManagedByLabel] != "kuba" {
+		t.Errorf("expected the inventory labels, got %v", labels)
+	}
+}
+
+func TestWriteInventoryClientDryRun(t *testing.T) {
+	dynamicClient := newFakeDynamicClient()
+	objects := []InventoryObject{{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "settings"}}
+
+	if err := WriteInventory(dynamicClient, "", "shop", objects, DryRunClient); err != nil {
+		t.Fatalf("WriteInventory returned error: %v", err)
+	}
+	if len(dynamicClient.Actions()) != 0 {
+		t.Errorf("expected no requests on a client dry run, got %v", dynamicClient.Actions())
+	}
+}
+
+func TestInventoryFromResults(t *testing.T) {
+	settings := newConfigMap("settings", "default")
+	existing := newConfigMap("existing", "default")
+	broken := newConfigMap("broken", "default")
+
+	objects, complete := InventoryFromCreate([]CreateResult{
+		{Object: settings},
+		{Object: existing, Err: apierrors.NewAlreadyExists(configMapsGVR.GroupResource(), "existing")},
+	})
+	if len(objects) != 2 || !complete {
+		t.Errorf("expected existing objects to stay in a complete inventory, got %v, %v", objects, complete)
+	}
+
+	objects, complete = InventoryFromApply([]ApplyResult{
+		{Object: settings, Action: ApplyUnchanged},
+		{Object: broken, Err: errors.New("apply failed")},
+	})
+	if len(objects) != 1 || objects[0].Name != "settings" || complete {
+		t.Errorf("expected only the applied object and an incomplete inventory, got %v, %v", objects, complete)
+	}
+}
+
+func TestPruneCandidatesAndMerge(t *testing.T) {
+	web := InventoryObject{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "web"}
+	worker := InventoryObject{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "worker"}
+	settings := InventoryObject{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "settings"}
+
+	prune := PruneCandidates([]InventoryObject{web, worker, settings}, []InventoryObject{web})
+	if len(prune) != 2 || prune[0] != worker || prune[1] != settings {
+		t.Errorf("expected worker and settings to be pruned, got %v", prune)
+	}
+
+	merged := MergeInventory([]InventoryObject{web, worker}, []InventoryObject{web, settings})
+	if len(merged) != 3 {
+		t.Errorf("expected the union of both inventories, got %v", merged)
+	}
+}
+
+func TestPruneCandidatesIgnoreAPIVersion(t *testing.T) {
+	old := InventoryObject{APIVersion: "autoscaling/v2beta2", Kind: "HorizontalPodAutoscaler", Namespace: "default", Name: "web"}
+	moved := InventoryObject{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler", Namespace: "default", Name: "web"}
+	other := InventoryObject{APIVersion: "example.com/v2", Kind: "HorizontalPodAutoscaler", Namespace: "default", Name: "web"}
+
+	if prune := PruneCandidates([]InventoryObject{old}, []InventoryObject{moved}); len(prune) != 0 {
+		t.Errorf("expected an apiVersion change to prune nothing, got %v", prune)
+	}
+	if merged := MergeInventory([]InventoryObject{old}, []InventoryObject{moved}); len(merged) != 1 || merged[0] != moved {
+		t.Errorf("expected the inventory to record the new version only, got %v", merged)
+	}
+	if prune := PruneCandidates([]InventoryObject{other}, []InventoryObject{moved}); len(prune) != 1 {
+		t.Errorf("expected a kind of another group to be pruned, got %v", prune)
+	}
+}
+
+func TestPruneObjects(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(
+		newConfigMap("settings", "default"),
+		newObject("apps/v1", "Deployment", "worker", "default", nil),
+	)
+	objects := []InventoryObject{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "settings"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "worker"},
+		{APIVersion: "v1", Kind: "Service", Namespace: "default", Name: "already-gone"},
+	}
+
+	preview := PruneObjects(dynamicClient, newTestMapper(), objects, DryRunClient)
+	if len(preview) != 3 {
+		t.Fatalf("expected 3 results, got %+v", preview)
+	}
+	if _, err := dynamicClient.Resource(deploymentsGVR).Namespace("default").Get(context.TODO(), "worker", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected a client dry run to keep the deployment, got %v", err)
+	}
+
+	results := PruneObjects(dynamicClient, newTestMapper(), objects, DryRunNone)
+	if len(results) != 3 || results[0].Kind != "Deployment" || results[2].Kind != "ConfigMap" {
+		t.Fatalf("expected workloads to be pruned before configuration, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("pruning %s %s: %v", result.Kind, result.Name, result.Err)
+		}
+	}
+	if _, err := dynamicClient.Resource(configMapsGVR).Namespace("default").Get(context.TODO(), "settings", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the config map to be pruned, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// LogOptions selects the log lines to read from each container.
+type LogOptions struct {
+	// Container restricts the logs to one container, all when empty.
+	Container string
+	Follow    bool
+	// Since only returns lines newer than this, all when zero.
+	Since time.Duration
+	// Tail only returns the last lines of each container, all when negative.
+	Tail     int64
+	Previous bool
+}
+
+// LogLine is a single line logged by a container.
+type LogLine struct {
+	Pod       string
+	Container string
+	Text      string
+}
+
+// DeploymentSelector returns the label selector of a deployment, which
+// matches the pods it manages.
+func DeploymentSelector(clientset kubernetes.Interface, namespace string, deploymentName string) (string, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, v1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	selector := getLabelSelector(deployment.Spec.Selector)
+	if selector == "" {
+		return "", fmt.Errorf("deployment %s has no matchLabels selector", deploymentName)
+	}
+	return selector, nil
+}
+
+// StreamPodLogs passes every log line of the containers of a pod to out.
+// With Follow it returns once all streams end or ctx is done.
+func StreamPodLogs(ctx context.Context, clientset kubernetes.Interface, namespace string, podName string, options LogOptions, out func(LogLine)) error {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, v1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	streamer := newLogStreamer(ctx, clientset, namespace, options, out)
+	if !streamer.streamPod(pod, false) {
+		return fmt.Errorf("container %s not found in pod %s", options.Container, podName)
+	}
+	return streamer.wait()
+}
+
+// StreamSelectorLogs passes the log lines of every pod matching selector to
+// out, one goroutine per container; out is never called concurrently. With
+// Follow it keeps watching the pods, picking up the containers of new pods,
+// such as the ones of a rollout, once they start, and returns when ctx is
+// done. Pods without options.Container are skipped.
+func StreamSelectorLogs(ctx context.Context, clientset kubernetes.Interface, namespace string, selector string, options LogOptions, out func(LogLine)) error {
+	streamer := newLogStreamer(ctx, clientset, namespace, options, out)
+	if !options.Follow {
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return err
+		}
+		if len(pods.Items) == 0 {
+			return fmt.Errorf("no pods match selector %s", selector)
+		}
+		for i := range pods.Items {
+			streamer.streamPod(&pods.Items[i], false)
+		}
+		return streamer.wait()
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *v1.ListOptions) { options.LabelSelector = selector }))
+	podInformer := factory.Core().V1().Pods().Informer()
+	_, err := podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				streamer.streamPod(pod, true)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				streamer.streamPod(pod, true)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+	factory.Shutdown()
+	return streamer.wait()
+}
+
+// logStreamer runs one log stream per container and serializes their lines.
+type logStreamer struct {
+	ctx       context.Context
+	clientset kubernetes.Interface
+	namespace string
+	options   LogOptions
+	out       func(LogLine)
+
+	mu sync.Mutex
+	// started holds the containers being streamed, as pod/container.
+	started map[string]bool
+	// restarts holds the restart count of the last stream of a container,
+	// so that a restarted container is streamed again, but not an ended one.
+	restarts map[string]int32
+	errs     []error
+	streams  sync.WaitGroup
+}
+
+func newLogStreamer(ctx context.Context, clientset kubernetes.Interface, namespace string, options LogOptions, out func(LogLine)) *logStreamer {
+	return &logStreamer{
+		ctx:       ctx,
+		clientset: clientset,
+		namespace: namespace,
+		options:   options,
+		out:       out,
+		started:   map[string]bool{},
+		restarts:  map[string]int32{},
+	}
+}
+
+// streamPod starts streaming the containers of pod that are not streamed
+// yet, or that restarted since their last stream ended. When waitForStart is set, containers that have not started are left
+// for a later call. It reports whether pod has the requested container.
+func (s *logStreamer) streamPod(pod *corev1.Pod, waitForStart bool) bool {
+	found := false
+	for _, container := range pod.Spec.Containers {
+		if s.options.Container != "" && container.Name != s.options.Container {
+			continue
+		}
+		found = true
+		if waitForStart && s.options.Follow && !s.options.Previous && !containerStarted(pod, container.Name) {
+			continue
+		}
+
+		key := pod.Name + "/" + container.Name
+		restarts := containerRestarts(pod, container.Name)
+		s.mu.Lock()
+		if previous, streamed := s.restarts[key]; s.started[key] || streamed && previous == restarts || s.ctx.Err() != nil {
+			s.mu.Unlock()
+			continue
+		}
+		s.started[key] = true
+		s.restarts[key] = restarts
+		s.streams.Add(1)
+		s.mu.Unlock()
+
+		go s.streamContainer(pod.Name, container.Name)
+	}
+	return found
+}
+
+func (s *logStreamer) streamContainer(podName string, containerName string) {
+	defer s.streams.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.started, podName+"/"+containerName)
+		s.mu.Unlock()
+	}()
+	logOptions := &corev1.PodLogOptions{
+		Container: containerName,
+		Follow:    s.options.Follow,
+		Previous:  s.options.Previous,
+	}
+	if s.options.Since > 0 {
+		seconds := int64(math.Ceil(s.options.Since.Seconds()))
+		logOptions.SinceSeconds = &seconds
+	}
+	if s.options.Tail >= 0 {
+		tail := s.options.Tail
+		logOptions.TailLines = &tail
+	}
+
+	err := s.readStream(podName, containerName, logOptions)
+	if err != nil && s.ctx.Err() == nil {
+		s.mu.Lock()
+		s.errs = append(s.errs, fmt.Errorf("reading logs of %s/%s: %w", podName, containerName, err))
+		s.mu.Unlock()
+	}
+}
+
+func (s *logStreamer) readStream(podName string, containerName string, logOptions *corev1.PodLogOptions) error {
+	stream, err := s.clientset.CoreV1().Pods(s.namespace).GetLogs(podName, logOptions).Stream(s.ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		s.mu.Lock()
+		s.out(LogLine{Pod: podName, Container: containerName, Text: scanner.Text()})
+		s.mu.Unlock()
+	}
+	return scanner.Err()
+}
+
+// wait blocks until every stream has ended and returns their errors.
+func (s *logStreamer) wait() error {
+	s.streams.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return errors.Join(s.errs...)
+}
+
+func containerRestarts(pod *corev1.Pod, containerName string) int32 {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName {
+			return status.RestartCount
+		}
+	}
+	return 0
+}
+
+func containerStarted(pod *corev1.Pod, containerName string) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName {
+			return status.State.Running != nil || status.State.Terminated != nil
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newRunningPod(name string, labels map[string]string, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+			Name:  container,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		})
+	}
+	return pod
+}
+
+// collectLines returns an out func for the streamers and the sources of
+// the lines it received, as pod/container.
+func collectLines() (func(LogLine), func() []string) {
+	var sources []string
+	return func(line LogLine) {
+			sources = append(sources, line.Pod+"/"+line.Container)
+		}, func() []string {
+			sort.Strings(sources)
+			return sources
+		}
+}
+
+func TestStreamPodLogs(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newRunningPod("web", nil, "app", "sidecar"))
+
+	out, sources := collectLines()
+	if err := StreamPodLogs(context.TODO(), clientset, "default", "web", LogOptions{Tail: -1}, out); err != nil {
+		t.Fatalf("StreamPodLogs returned error: %v", err)
+	}
+	if got := sources(); len(got) != 2 || got[0] != "web/app" || got[1] != "web/sidecar" {
+		t.Errorf("expected a line from each container, got %v", got)
+	}
+
+	out, sources = collectLines()
+	if err := StreamPodLogs(context.TODO(), clientset, "default", "web", LogOptions{Container: "sidecar", Tail: 10}, out); err != nil {
+		t.Fatalf("StreamPodLogs returned error: %v", err)
+	}
+	if got := sources(); len(got) != 1 || got[0] != "web/sidecar" {
+		t.Errorf("expected only the sidecar logs, got %v", got)
+	}
+
+	if err := StreamPodLogs(context.TODO(), clientset, "default", "web", LogOptions{Container: "missing"}, out); err == nil {
+		t.Error("expected an error for a missing container")
+	}
+}
+
+func TestStreamSelectorLogs(t *testing.T) {
+	labels := map[string]string{"app": "web"}
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		},
+		newRunningPod("web-1", labels, "app"),
+		newRunningPod("web-2", labels, "app"),
+		newRunningPod("db", map[string]string{"app": "db"}, "app"),
+	)
+
+	selector, err := DeploymentSelector(clientset, "default", "web")
+	if err != nil || selector != "app=web" {
+		t.Fatalf("expected the deployment selector, got %q, %v", selector, err)
+	}
+
+	out, sources := collectLines()
+	if err := StreamSelectorLogs(context.TODO(), clientset, "default", selector, LogOptions{Tail: -1}, out); err != nil {
+		t.Fatalf("StreamSelectorLogs returned error: %v", err)
+	}
+	if got := sources(); len(got) != 2 || got[0] != "web-1/app" || got[1] != "web-2/app" {
+		t.Errorf("expected the logs of both web pods, got %v", got)
+	}
+
+	if err := StreamSelectorLogs(context.TODO(), clientset, "default", "app=none", LogOptions{Tail: -1}, out); err == nil {
+		t.Error("expected an error when no pods match")
+	}
+}
+
+func TestStreamSelectorLogsFollowPicksUpNewPods(t *testing.T) {
+	labels := map[string]string{"app": "web"}
+	pending := newRunningPod("web-2", labels, "app")
+	pending.Status.ContainerStatuses = nil
+	clientset := fake.NewSimpleClientset(newRunningPod("web-1", labels, "app"), pending)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lines := make(chan LogLine, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- StreamSelectorLogs(ctx, clientset, "default", "app=web", LogOptions{Follow: true, Tail: -1}, func(line LogLine) { lines <- line })
+	}()
+
+	if line := <-lines; line.Pod != "web-1" {
+		t.Fatalf("expected the running pod first, got %+v", line)
+	}
+	if _, err := clientset.CoreV1().Pods("default").UpdateStatus(context.TODO(), newRunningPod("web-2", labels, "app"), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("starting pod: %v", err)
+	}
+	if line := <-lines; line.Pod != "web-2" {
+		t.Errorf("expected the started pod to be picked up, got %+v", line)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("StreamSelectorLogs returned error: %v", err)
+	}
+}
+
+func TestStreamSelectorLogsFollowsRestartedContainers(t *testing.T) {
+	labels := map[string]string{"app": "web"}
+	clientset := fake.NewSimpleClientset(newRunningPod("web-1", labels, "app"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lines := make(chan LogLine, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- StreamSelectorLogs(ctx, clientset, "default", "app=web", LogOptions{Follow: true, Tail: -1}, func(line LogLine) { lines <- line })
+	}()
+	<-lines
+
+	// Updates without a restart do not stream the ended container again.
+	clientset.CoreV1().Pods("default").UpdateStatus(context.TODO(), newRunningPod("web-1", labels, "app"), metav1.UpdateOptions{})
+	restarted := newRunningPod("web-1", labels, "app")
+	restarted.Status.ContainerStatuses[0].RestartCount = 1
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	for streamed := false; !streamed; {
+		select {
+		case line := <-lines:
+			if line.Pod != "web-1" {
+				t.Fatalf("unexpected line %+v", line)
+			}
+			streamed = true
+		case <-ticker.C:
+			clientset.CoreV1().Pods("default").UpdateStatus(context.TODO(), restarted, metav1.UpdateOptions{})
+		case <-ctx.Done():
+			t.Fatal("expected the restarted container to be streamed again")
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("StreamSelectorLogs returned error: %v", err)
+	}
+	if len(lines) != 0 {
+		t.Errorf("expected one stream per restart, got %d more lines", len(lines))
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// StdinPath reads manifests from standard input when given as a path.
+const StdinPath = "-"
+
+// manifestExtensions are the files picked up when a path is a directory.
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// ManifestOptions says where manifests are read from. Paths can be files,
+// directories or StdinPath; directories are read one level deep unless
+// Recursive is set.
+type ManifestOptions struct {
+	Paths     []string
+	Recursive bool
+	Stdin     io.Reader
+}
+
+// readManifests decodes every object of every manifest and orders them
+// across files, so that the kinds other objects depend on come first.
+func readManifests(manifests ManifestOptions) ([]*unstructured.Unstructured, error) {
+	if len(manifests.Paths) == 0 {
+		return nil, fmt.Errorf("no manifest paths given")
+	}
+
+	var objects []*unstructured.Unstructured
+	stdinRead := false
+	for _, path := range manifests.Paths {
+		if path == StdinPath {
+			if stdinRead {
+				continue
+			}
+			stdinRead = true
+			fileObjects, err := readManifestFrom("stdin", manifests.Stdin)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, fileObjects...)
+			continue
+		}
+
+		files, err := manifestFiles(path, manifests.Recursive)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			fileObjects, err := readManifestFile(file)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, fileObjects...)
+		}
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return kindPriority(objects[i].GetKind()) < kindPriority(objects[j].GetKind())
+	})
+	return objects, nil
+}
+
+// manifestFiles expands path into the manifest files it names. A file is
+// used as is, whatever its extension; a directory yields its .yaml, .yml
+// and .json files in lexical order.
+func manifestFiles(path string, recursive bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if file != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isManifestFile(file) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no manifests found in %s", path)
+	}
+	return files, nil
+}
+
+func isManifestFile(file string) bool {
+	extension := strings.ToLower(filepath.Ext(file))
+	for _, manifestExtension := range manifestExtensions {
+		if extension == manifestExtension {
+			return true
+		}
+	}
+	return false
+}
+
+func readManifestFile(file string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readManifestFrom(file, f)
+}
+
+func readManifestFrom(name string, reader io.Reader) ([]*unstructured.Unstructured, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("reading %s: no input", name)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	objects, err := decodeDocuments(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrInvalidManifest, name, err)
+	}
+	return objects, nil
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func manifestPaths(paths ...string) ManifestOptions {
+	return ManifestOptions{Paths: paths}
+}
+
+// writeManifestTree writes files, keyed by their path relative to a new
+// temporary directory, and returns the directory.
+func writeManifestTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("writing manifest: %v", err)
+		}
+	}
+	return dir
+}
+
+func objectNames(t *testing.T, manifests ManifestOptions) []string {
+	t.Helper()
+	objects, err := readManifests(manifests)
+	if err != nil {
+		t.Fatalf("readManifests returned error: %v", err)
+	}
+	var names []string
+	for _, obj := range objects {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	return names
+}
+
+const nestedDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`
+
+func TestReadManifestsDirectory(t *testing.T) {
+	dir := writeManifestTree(t, map[string]string{
+		"app/deployment.yaml": nestedDeployment,
+		"service.yml":         "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+		"namespace.json":      `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "team-a"}}`,
+		"README.md":           "not a manifest",
+	})
+
+	names := objectNames(t, manifestPaths(dir))
+	if strings.Join(names, ",") != "Namespace/team-a,Service/web" {
+		t.Errorf("expected only the top level manifests, got %v", names)
+	}
+
+	names = objectNames(t, ManifestOptions{Paths: []string{dir}, Recursive: true})
+	if strings.Join(names, ",") != "Namespace/team-a,Service/web,Deployment/web" {
+		t.Errorf("expected the nested deployment with -R, ordered across files, got %v", names)
+	}
+}
+
+func TestReadManifestsMultiplePathsAndStdin(t *testing.T) {
+	dir := writeManifestTree(t, map[string]string{"deployment.yaml": nestedDeployment})
+	stdin := strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n")
+
+	names := objectNames(t, ManifestOptions{
+		Paths: []string{filepath.Join(dir, "deployment.yaml"), StdinPath, StdinPath},
+		Stdin: stdin,
+	})
+	if strings.Join(names, ",") != "ConfigMap/settings,Deployment/web" {
+		t.Errorf("expected stdin to be read once and ordered with the file, got %v", names)
+	}
+}
+
+func TestReadManifestsJSONList(t *testing.T) {
+	dir := writeManifestTree(t, map[string]string{"list.json": `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}},
+    {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}}
+  ]
+}`})
+
+	names := objectNames(t, manifestPaths(dir))
+	if strings.Join(names, ",") != "Service/web,Deployment/web" {
+		t.Errorf("expected the list items, got %v", names)
+	}
+}
+
+func TestReadManifestsErrors(t *testing.T) {
+	empty := t.TempDir()
+	if _, err := readManifests(manifestPaths(empty)); err == nil {
+		t.Error("expected an error for a directory without manifests")
+	}
+	if _, err := readManifests(ManifestOptions{}); err == nil {
+		t.Error("expected an error without paths")
+	}
+
+	dir := writeManifestTree(t, map[string]string{"broken.json": `{"kind": `})
+	if _, err := readManifests(manifestPaths(dir)); !errors.Is(err, ErrInvalidManifest) {
+		t.Errorf("expected ErrInvalidManifest, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+// ExecOptions describes a command to run in a container and its streams.
+type ExecOptions struct {
+	// Container runs the command in this container, the pod's default
+	// container when empty.
+	Container string
+	Command   []string
+	Stdin     io.Reader
+	Stdout    io.Writer
+	Stderr    io.Writer
+	// TTY allocates a terminal; its output all goes to Stdout.
+	TTY               bool
+	TerminalSizeQueue remotecommand.TerminalSizeQueue
+}
+
+// ExecResult is the outcome of running a command in one pod.
+type ExecResult struct {
+	Pod      string
+	Output   string
+	ExitCode int
+	Err      error
+}
+
+// newExecutor opens the exec streams, over WebSocket when the server
+// supports it and over SPDY otherwise. Tests replace it.
+var newExecutor = func(config *rest.Config, execURL *url.URL) (remotecommand.Executor, error) {
+	websocket, err := remotecommand.NewWebSocketExecutor(config, "GET", execURL.String())
+	if err != nil {
+		return nil, err
+	}
+	spdy, err := remotecommand.NewSPDYExecutor(config, "POST", execURL)
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewFallbackExecutor(websocket, spdy, httpstream.IsUpgradeFailure)
+}
+
+// ExecInPod runs a command in a container of a pod, streaming its input
+// and output. A command that exits with a non-zero status returns an
+// exec.ExitError carrying the status.
+func ExecInPod(ctx context.Context, config *rest.Config, namespace string, podName string, options ExecOptions) error {
+	client, err := corev1client.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	execURL := client.RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: options.Container,
+			Command:   options.Command,
+			Stdin:     options.Stdin != nil,
+			Stdout:    options.Stdout != nil,
+			Stderr:    options.Stderr != nil && !options.TTY,
+			TTY:       options.TTY,
+		}, scheme.ParameterCodec).
+		URL()
+
+	executor, err := newExecutor(config, execURL)
+	if err != nil {
+		return err
+	}
+	streamOptions := remotecommand.StreamOptions{
+		Stdin:             options.Stdin,
+		Stdout:            options.Stdout,
+		Tty:               options.TTY,
+		TerminalSizeQueue: options.TerminalSizeQueue,
+	}
+	if !options.TTY {
+		streamOptions.Stderr = options.Stderr
+	}
+	return executor.StreamWithContext(ctx, streamOptions)
+}
+
+// ExecInPods runs a non-interactive command in every running pod matching
+// selector, one after another, and collects the combined output and exit
+// status of each. Pods that are not running are reported as failed.
+func ExecInPods(ctx context.Context, config *rest.Config, clientset kubernetes.Interface, namespace string, selector string, container string, command []string) ([]ExecResult, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no pods match selector %s", selector)
+	}
+
+	var results []ExecResult
+	for _, pod := range pods.Items {
+		result := ExecResult{Pod: pod.Name}
+		if pod.Status.Phase != corev1.PodRunning {
+			result.ExitCode = -1
+			result.Err = fmt.Errorf("pod is %s", pod.Status.Phase)
+			results = append(results, result)
+			continue
+		}
+
+		output := &lockedBuffer{}
+		err := ExecInPod(ctx, config, pod.Namespace, pod.Name, ExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    output,
+			Stderr:    output,
+		})
+		result.Output = output.String()
+		var exitErr exec.ExitError
+		switch {
+		case err == nil:
+		case errors.As(err, &exitErr):
+			result.ExitCode = exitErr.ExitStatus()
+		default:
+			result.ExitCode = -1
+			result.Err = err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// lockedBuffer collects stdout and stderr, which are copied concurrently.
+type lockedBuffer struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffer.String()
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+// fakeExecutor answers every exec with the pod name from the URL and exits
+// with the status listed for the pod.
+type fakeExecutor struct {
+	url       *url.URL
+	exitCodes map[string]int
+}
+
+func (e *fakeExecutor) Stream(options remotecommand.StreamOptions) error {
+	return e.StreamWithContext(context.Background(), options)
+}
+
+func (e *fakeExecutor) StreamWithContext(ctx context.Context, options remotecommand.StreamOptions) error {
+	parts := strings.Split(e.url.Path, "/")
+	pod := parts[len(parts)-2]
+	fmt.Fprintf(options.Stdout, "%s ran %s", pod, strings.Join(e.url.Query()["command"], " "))
+	if code := e.exitCodes[pod]; code != 0 {
+		fmt.Fprint(options.Stderr, " and failed")
+		return exec.CodeExitError{Err: fmt.Errorf("command terminated with exit code %d", code), Code: code}
+	}
+	return nil
+}
+
+func useFakeExecutor(t *testing.T, exitCodes map[string]int) {
+	original := newExecutor
+	newExecutor = func(config *rest.Config, execURL *url.URL) (remotecommand.Executor, error) {
+		return &fakeExecutor{url: execURL, exitCodes: exitCodes}, nil
+	}
+	t.Cleanup(func() { newExecutor = original })
+}
+
+func TestExecInPod(t *testing.T) {
+	useFakeExecutor(t, map[string]int{"broken": 3})
+	config := &rest.Config{Host: "https://cluster.example"}
+
+	var stdout strings.Builder
+	err := ExecInPod(context.TODO(), config, "default", "web", ExecOptions{Container: "app", Command: []string{"ls", "/"}, Stdout: &stdout})
+	if err != nil {
+		t.Fatalf("ExecInPod returned error: %v", err)
+	}
+	if stdout.String() != "web ran ls /" {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+
+	err = ExecInPod(context.TODO(), config, "default", "broken", ExecOptions{Command: []string{"false"}, Stdout: &stdout, Stderr: &stdout})
+	var exitErr exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 3 {
+		t.Errorf("expected exit status 3, got %v", err)
+	}
+}
+
+func TestExecInPods(t *testing.T) {
+	useFakeExecutor(t, map[string]int{"web-2": 1})
+	labels := map[string]string{"app": "web"}
+	running := corev1.PodStatus{Phase: corev1.PodRunning}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: labels}, Status: running},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default", Labels: labels}, Status: running},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-3", Namespace: "default", Labels: labels}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}, Status: running},
+	)
+
+	results, err := ExecInPods(context.TODO(), &rest.Config{Host: "https://cluster.example"}, clientset, "default", "app=web", "", []string{"hostname"})
+	if err != nil {
+		t.Fatalf("ExecInPods returned error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected a result per matching pod, got %+v", results)
+	}
+	if results[0].ExitCode != 0 || results[0].Err != nil || results[0].Output != "web-1 ran hostname" {
+		t.Errorf("unexpected result for web-1: %+v", results[0])
+	}
+	if results[1].ExitCode != 1 || results[1].Err != nil || results[1].Output != "web-2 ran hostname and failed" {
+		t.Errorf("unexpected result for web-2: %+v", results[1])
+	}
+	if results[2].Err == nil {
+		t.Errorf("expected an error for the pending pod, got %+v", results[2])
+	}
+
+	if _, err := ExecInPods(context.TODO(), &rest.Config{Host: "https://cluster.example"}, clientset, "default", "app=none", "", []string{"hostname"}); err == nil {
+		t.Error("expected an error when no pods match")
+	}
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// ForwardPort forwards a local port to a port of the pod. Remote is a port
+// number or a port name. Local is 0 to let the system pick a port and
+// negative to use the same number as the resolved remote port.
+type ForwardPort struct {
+	Local  int
+	Remote string
+}
+
+func (p ForwardPort) String() string {
+	return fmt.Sprintf("%d:%s", p.Local, p.Remote)
+}
+
+// PortForwardTarget is the pod to forward to, either a fixed pod or any
+// ready pod matching Selector.
+type PortForwardTarget struct {
+	Pod      string
+	Selector string
+	Ports    []ForwardPort
+}
+
+// PortForwardEvent reports what the forwarder is doing.
+type PortForwardEvent struct {
+	Pod string
+	// Ports are the forwarded ports once connected, with the local ports
+	// the system picked.
+	Ports []ForwardPort
+	// Err is set when the connection to Pod was lost.
+	Err error
+}
+
+// reconnectDelay is how long the forwarder waits before looking for a
+// new pod after losing the connection.
+var reconnectDelay = time.Second
+
+// forwardPorts forwards ports to a pod until stop is closed or the
+// connection is lost, calling ready with the ports once listening. Tests
+// replace it.
+var forwardPorts = func(config *rest.Config, namespace string, podName string, ports []ForwardPort, stop <-chan struct{}, ready func([]ForwardPort)) error {
+	client, err := corev1client.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return err
+	}
+	forwardURL := client.RESTClient().Post().Resource("pods").Namespace(namespace).Name(podName).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, forwardURL)
+
+	var specs []string
+	for _, port := range ports {
+		specs = append(specs, port.String())
+	}
+	readyChan, done := make(chan struct{}), make(chan struct{})
+	defer close(done)
+	forwarder, err := portforward.New(dialer, specs, stop, readyChan, io.Discard, io.Discard)
+	if err != nil {
+		return err
+	}
+	go func() {
+		select {
+		case <-readyChan:
+		case <-stop:
+			return
+		case <-done:
+			return
+		}
+		forwarded, err := forwarder.GetPorts()
+		if err != nil {
+			return
+		}
+		listening := make([]ForwardPort, len(forwarded))
+		for i, port := range forwarded {
+			listening[i] = ForwardPort{Local: int(port.Local), Remote: strconv.Itoa(int(port.Remote))}
+		}
+		ready(listening)
+	}()
+	return forwarder.ForwardPorts()
+}
+
+// ParsePortPairs parses "local:remote" pairs. A single port forwards the
+// same port and an empty local port lets the system pick one.
+func ParsePortPairs(pairs []string) ([]ForwardPort, error) {
+	var ports []ForwardPort
+	for _, pair := range pairs {
+		local, remote, found := strings.Cut(pair, ":")
+		if !found {
+			local, remote = "", local
+		}
+		if remote == "" {
+			return nil, fmt.Errorf("invalid port pair %q, expected [local:]remote", pair)
+		}
+		port := ForwardPort{Remote: remote}
+		if !found {
+			port.Local = -1
+		}
+		if local != "" {
+			number, err := strconv.Atoi(local)
+			if err != nil || number < 0 || number > 65535 {
+				return nil, fmt.Errorf("invalid local port in %q", pair)
+			}
+			port.Local = number
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 0 {
+		return nil, errors.New("no ports to forward")
+	}
+	return ports, nil
+}
+
+// DeploymentForwardTarget forwards ports to a ready pod of a deployment.
+func DeploymentForwardTarget(clientset kubernetes.Interface, namespace string, deploymentName string, ports []ForwardPort) (PortForwardTarget, error) {
+	selector, err := DeploymentSelector(clientset, namespace, deploymentName)
+	if err != nil {
+		return PortForwardTarget{}, err
+	}
+	return PortForwardTarget{Selector: selector, Ports: ports}, nil
+}
+
+// ServiceForwardTarget forwards service ports, given by number or name, to
+// the target ports of a ready pod behind the service, as the service would.
+func ServiceForwardTarget(clientset kubernetes.Interface, namespace string, serviceName string, ports []ForwardPort) (PortForwardTarget, error) {
+	serviceDetailsList, err := ServiceDetailsRetrieve(clientset, namespace, serviceName)
+	if err != nil {
+		return PortForwardTarget{}, err
+	}
+	service := serviceDetailsList[0]
+	if len(service.Selector) == 0 {
+		return PortForwardTarget{}, fmt.Errorf("service %s has no selector, so it has no pods to forward to", serviceName)
+	}
+
+	target := PortForwardTarget{Selector: labels.SelectorFromSet(service.Selector).String()}
+	for _, port := range ports {
+		servicePort, found := findServicePort(service.Ports, port.Remote)
+		if !found {
+			return PortForwardTarget{}, fmt.Errorf("service %s has no port %s", serviceName, port.Remote)
+		}
+		remote := servicePort.TargetPort
+		if remote == "" || remote == "0" {
+			remote = strconv.Itoa(int(servicePort.Port))
+		}
+		local := port.Local
+		if local < 0 {
+			local = int(servicePort.Port)
+		}
+		target.Ports = append(target.Ports, ForwardPort{Local: local, Remote: remote})
+	}
+	return target, nil
+}
+
+func findServicePort(ports []ServicePortDetails, port string) (ServicePortDetails, bool) {
+	for _, servicePort := range ports {
+		if servicePort.Name == port || strconv.Itoa(int(servicePort.Port)) == port {
+			return servicePort, true
+		}
+	}
+	return ServicePortDetails{}, false
+}
+
+// PortForward forwards the target ports until ctx is done. For selector
+// targets it picks a ready pod and, when the connection is lost or the pod
+// went away, it picks a new one and keeps the same local ports. Other
+// errors, such as local ports that cannot be listened on, are returned.
+// Every connection and lost connection is passed to report.
+func PortForward(ctx context.Context, config *rest.Config, clientset kubernetes.Interface, namespace string, target PortForwardTarget, report func(PortForwardEvent)) error {
+	var mu sync.Mutex
+	ports := append([]ForwardPort{}, target.Ports...)
+	for {
+		pod, err := forwardPod(ctx, clientset, namespace, target)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		podName := pod.Name
+		mu.Lock()
+		remotePorts, err := containerPorts(pod, ports)
+		mu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		stop, forwarded := make(chan struct{}), make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-forwarded:
+			}
+			close(stop)
+		}()
+		err = forwardPorts(config, namespace, podName, remotePorts, stop, func(listening []ForwardPort) {
+			// Keep the local ports the system picked for the next pod.
+			mu.Lock()
+			for i := range ports {
+				if i < len(listening) {
+					ports[i].Local = listening[i].Local
+				}
+			}
+			mu.Unlock()
+			report(PortForwardEvent{Pod: podName, Ports: listening})
+		})
+		close(forwarded)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err == nil {
+			err = portforward.ErrLostConnectionToPod
+		}
+		retry := errors.Is(err, portforward.ErrLostConnectionToPod) || podGone(ctx, clientset, namespace, podName)
+		if target.Pod != "" || !retry {
+			return fmt.Errorf("forwarding to pod %s: %w", podName, err)
+		}
+		report(PortForwardEvent{Pod: podName, Err: err})
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// forwardPod returns the fixed pod of target, or waits for a running,
+// ready pod matching its selector, preferring the newest one.
+func forwardPod(ctx context.Context, clientset kubernetes.Interface, namespace string, target PortForwardTarget) (*corev1.Pod, error) {
+	if target.Pod != "" {
+		return clientset.CoreV1().Pods(namespace).Get(ctx, target.Pod, v1.GetOptions{})
+	}
+	for {
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{LabelSelector: target.Selector})
+		if err != nil {
+			return nil, err
+		}
+		var ready []corev1.Pod
+		for _, pod := range pods.Items {
+			if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning && isPodReady(&pod) {
+				ready = append(ready, pod)
+			}
+		}
+		if len(ready) > 0 {
+			sort.Slice(ready, func(i, j int) bool {
+				return ready[i].CreationTimestamp.After(ready[j].CreationTimestamp.Time)
+			})
+			return &ready[0], nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// podGone reports whether the pod was deleted, or is being deleted, or no
+// longer runs.
+func podGone(ctx context.Context, clientset kubernetes.Interface, namespace string, podName string) bool {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, v1.GetOptions{})
+	if err != nil {
+		return apierrors.IsNotFound(err)
+	}
+	return pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning
+}
+
+// containerPorts resolves named remote ports to the container ports of pod.
+func containerPorts(pod *corev1.Pod, ports []ForwardPort) ([]ForwardPort, error) {
+	resolved := make([]ForwardPort, len(ports))
+	for i, port := range ports {
+		resolved[i] = port
+		number, err := strconv.Atoi(port.Remote)
+		if err != nil {
+			containerPort, found := namedContainerPort(pod, port.Remote)
+			if !found {
+				return nil, fmt.Errorf("pod %s has no container port named %s", pod.Name, port.Remote)
+			}
+			number = int(containerPort)
+			resolved[i].Remote = strconv.Itoa(number)
+		}
+		if port.Local < 0 {
+			resolved[i].Local = number
+		}
+	}
+	return resolved, nil
+}
+
+func namedContainerPort(pod *corev1.Pod, name string) (int32, bool) {
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == name {
+				return port.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+)
+
+func newReadyPod(name string, labels map[string]string, created time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels, CreationTimestamp: metav1.NewTime(created)},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "app",
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+		}}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestParsePortPairs(t *testing.T) {
+	ports, err := ParsePortPairs([]string{"8080:80", "9090", ":http"})
+	if err != nil {
+		t.Fatalf("ParsePortPairs returned error: %v", err)
+	}
+	expected := []ForwardPort{{Local: 8080, Remote: "80"}, {Local: -1, Remote: "9090"}, {Local: 0, Remote: "http"}}
+	for i, port := range expected {
+		if ports[i] != port {
+			t.Errorf("pair %d: expected %+v, got %+v", i, port, ports[i])
+		}
+	}
+
+	for _, pairs := range [][]string{{"8080:"}, {"x:80"}, {"70000:80"}, nil} {
+		if _, err := ParsePortPairs(pairs); err == nil {
+			t.Errorf("expected an error for %q", pairs)
+		}
+	}
+}
+
+func TestServiceForwardTarget(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "web"},
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromString("http")},
+				{Name: "metrics", Port: 9090, TargetPort: intstr.FromInt32(9100)},
+				{Name: "admin", Port: 8443},
+			},
+		},
+	})
+
+	ports, _ := ParsePortPairs([]string{"8000:80", "metrics", "admin"})
+	target, err := ServiceForwardTarget(clientset, "default", "web", ports)
+	if err != nil {
+		t.Fatalf("ServiceForwardTarget returned error: %v", err)
+	}
+	if target.Selector != "app=web" {
+		t.Errorf("expected the service selector, got %q", target.Selector)
+	}
+	expected := []ForwardPort{{Local: 8000, Remote: "http"}, {Local: 9090, Remote: "9100"}, {Local: 8443, Remote: "8443"}}
+	for i, port := range expected {
+		if target.Ports[i] != port {
+			t.Errorf("port %d: expected %+v, got %+v", i, port, target.Ports[i])
+		}
+	}
+
+	if _, err := ServiceForwardTarget(clientset, "default", "web", []ForwardPort{{Local: 1, Remote: "5432"}}); err == nil {
+		t.Error("expected an error for a port the service does not expose")
+	}
+}
+
+func TestPortForwardReconnectsToNewPod(t *testing.T) {
+	labels := map[string]string{"app": "web"}
+	clientset := fake.NewSimpleClientset(newReadyPod("web-1", labels, time.Now().Add(-time.Hour)))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	originalForward, originalDelay := forwardPorts, reconnectDelay
+	t.Cleanup(func() { forwardPorts, reconnectDelay = originalForward, originalDelay })
+	reconnectDelay = 10 * time.Millisecond
+
+	var forwarded []string
+	forwardPorts = func(config *rest.Config, namespace string, podName string, ports []ForwardPort, stop <-chan struct{}, ready func([]ForwardPort)) error {
+		forwarded = append(forwarded, podName+" "+ports[0].String())
+		ready([]ForwardPort{{Local: 41000, Remote: ports[0].Remote}})
+		if podName == "web-1" {
+			// The pod is replaced during a rollout.
+			clientset.CoreV1().Pods("default").Delete(context.TODO(), "web-1", metav1.DeleteOptions{})
+			clientset.CoreV1().Pods("default").Create(context.TODO(), newReadyPod("web-2", labels, time.Now()), metav1.CreateOptions{})
+			return portforward.ErrLostConnectionToPod
+		}
+		cancel()
+		<-stop
+		return nil
+	}
+
+	var events []PortForwardEvent
+	target := PortForwardTarget{Selector: "app=web", Ports: []ForwardPort{{Local: 0, Remote: "http"}}}
+	if err := PortForward(ctx, &rest.Config{}, clientset, "default", target, func(event PortForwardEvent) { events = append(events, event) }); err != nil {
+		t.Fatalf("PortForward returned error: %v", err)
+	}
+
+	if len(forwarded) != 2 || forwarded[0] != "web-1 0:8080" || forwarded[1] != "web-2 41000:8080" {
+		t.Errorf("expected to reconnect to the new pod on the same local port, got %v", forwarded)
+	}
+	if len(events) != 3 || !errors.Is(events[1].Err, portforward.ErrLostConnectionToPod) {
+		t.Errorf("expected connect, lost and reconnect events, got %+v", events)
+	}
+}
+
+func TestPortForwardFixedPodDoesNotReconnect(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newReadyPod("web-1", nil, time.Now()))
+	originalForward := forwardPorts
+	t.Cleanup(func() { forwardPorts = originalForward })
+	forwardPorts = func(config *rest.Config, namespace string, podName string, ports []ForwardPort, stop <-chan struct{}, ready func([]ForwardPort)) error {
+		return portforward.ErrLostConnectionToPod
+	}
+
+	target := PortForwardTarget{Pod: "web-1", Ports: []ForwardPort{{Local: -1, Remote: "8080"}}}
+	err := PortForward(context.TODO(), &rest.Config{}, clientset, "default", target, func(PortForwardEvent) {})
+	if !errors.Is(err, portforward.ErrLostConnectionToPod) {
+		t.Errorf("expected the lost connection error, got %v", err)
+	}
+}
+
+func TestPortForwardReturnsListenErrors(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newReadyPod("web-1", map[string]string{"app": "web"}, time.Now()))
+	originalForward, originalDelay := forwardPorts, reconnectDelay
+	t.Cleanup(func() { forwardPorts, reconnectDelay = originalForward, originalDelay })
+	reconnectDelay = 10 * time.Millisecond
+
+	attempts := 0
+	forwardPorts = func(config *rest.Config, namespace string, podName string, ports []ForwardPort, stop <-chan struct{}, ready func([]ForwardPort)) error {
+		attempts++
+		return errors.New("unable to listen on any of the requested ports: [{8080 8080}]")
+	}
+
+	target := PortForwardTarget{Selector: "app=web", Ports: []ForwardPort{{Local: -1, Remote: "8080"}}}
+	err := PortForward(context.TODO(), &rest.Config{}, clientset, "default", target, func(PortForwardEvent) {})
+	if err == nil || !strings.Contains(err.Error(), "unable to listen") || attempts != 1 {
+		t.Errorf("expected the listen error after one attempt, got %v after %d", err, attempts)
+	}
+}
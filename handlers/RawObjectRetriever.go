@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// RawObject fetches an object exactly as the API server returns it, for
+// output formats that work on the API object rather than on kuba's summary.
+func RawObject(dynamicClient dynamic.Interface, mapper meta.RESTMapper, kind string, namespace string, name string) (*unstructured.Unstructured, error) {
+	mapping, err := resolveKind(mapper, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return resourceInterface(dynamicClient, mapping, namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// RawObjects lists the API objects of the given kinds in a namespace (every
+// namespace when empty) and returns them as a single v1 List.
+func RawObjects(dynamicClient dynamic.Interface, mapper meta.RESTMapper, namespace string, kinds ...string) (*unstructured.UnstructuredList, error) {
+	rawList := &unstructured.UnstructuredList{}
+	rawList.SetAPIVersion("v1")
+	rawList.SetKind("List")
+
+	for _, kind := range kinds {
+		mapping, err := resolveKind(mapper, kind)
+		if err != nil {
+			return nil, err
+		}
+
+		list, err := resourceInterface(dynamicClient, mapping, namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			if item.GetKind() == "" {
+				item.SetGroupVersionKind(mapping.GroupVersionKind)
+			}
+			rawList.Items = append(rawList.Items, item)
+		}
+	}
+
+	return rawList, nil
+}
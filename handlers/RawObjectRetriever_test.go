@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+)
+
+func TestRawObject(t *testing.T) {
+	service := newObject("v1", "Service", "web", "default", map[string]string{"app": "web"})
+	service.Object["spec"] = map[string]interface{}{"clusterIP": "10.96.0.10"}
+	dynamicClient := newFakeDynamicClient(service)
+
+	obj, err := RawObject(dynamicClient, newTestMapper(), "service", "default", "web")
+	if err != nil {
+		t.Fatalf("RawObject returned error: %v", err)
+	}
+	if obj.GetKind() != "Service" || obj.GetName() != "web" {
+		t.Errorf("unexpected object: %v", obj.Object)
+	}
+	if spec, _ := obj.Object["spec"].(map[string]interface{}); spec["clusterIP"] != "10.96.0.10" {
+		t.Errorf("expected the raw spec, got %v", obj.Object["spec"])
+	}
+}
+
+func TestRawObjects(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(
+		newObject("apps/v1", "Deployment", "web", "default", nil),
+		newObject("v1", "Service", "web", "default", nil),
+		newObject("v1", "Service", "dns", "kube-system", nil),
+	)
+
+	list, err := RawObjects(dynamicClient, newTestMapper(), "default", "deployments", "services")
+	if err != nil {
+		t.Fatalf("RawObjects returned error: %v", err)
+	}
+	if list.GetKind() != "List" || len(list.Items) != 2 {
+		t.Fatalf("expected a List with 2 items, got %+v", list)
+	}
+	if list.Items[0].GetKind() != "Deployment" || list.Items[1].GetKind() != "Service" {
+		t.Errorf("unexpected items: %s, %s", list.Items[0].GetKind(), list.Items[1].GetKind())
+	}
+}
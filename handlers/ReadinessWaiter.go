@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+var endpointsGVR = schema.GroupVersionResource{Version: "v1", Resource: "endpoints"}
+
+// WaitResult is the outcome of waiting for a single object.
+type WaitResult struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Err       error
+}
+
+// readinessCheck reports whether an object is ready. An error means it
+// never will be, for example a failed Job.
+type readinessCheck func(obj *unstructured.Unstructured) (bool, error)
+
+// WaitForReady watches an object until it is ready for its kind:
+// deployments have all replicas updated and available, jobs are complete,
+// pods are Ready and services have endpoints. Other kinds are ready once
+// they exist. The wait ends with ErrWaitTimeout when ctx expires.
+func WaitForReady(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, kind string, name string, namespace string) error {
+	mapping, err := resolveKind(mapper, kind)
+	if err != nil {
+		return err
+	}
+	return waitForMapping(ctx, dynamicClient, mapping, name, namespace)
+}
+
+// WaitForCondition watches an object until status.conditions holds a
+// condition of conditionType with conditionStatus, "True" when empty.
+func WaitForCondition(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, kind string, name string, namespace string, conditionType string, conditionStatus string) error {
+	mapping, err := resolveKind(mapper, kind)
+	if err != nil {
+		return err
+	}
+	if conditionStatus == "" {
+		conditionStatus = string(metav1.ConditionTrue)
+	}
+
+	check := func(obj *unstructured.Unstructured) (bool, error) {
+		status, found := conditionStatusOf(obj, conditionType)
+		return found && status == conditionStatus, nil
+	}
+	return watchUntil(ctx, dynamicClient, mapping.Resource, scopedNamespace(mapping, namespace), name, check)
+}
+
+// WaitForCreated waits for every object that was created successfully.
+// Failed creations and client dry runs have nothing to wait for.
+func WaitForCreated(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, results []CreateResult) []WaitResult {
+	var waits []WaitResult
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		waitResult := WaitResult{Kind: result.Kind, Name: result.Name, Namespace: result.Namespace}
+		mapping, err := mappingFor(mapper, result.Object.GroupVersionKind())
+		if err == nil {
+			err = waitForMapping(ctx, dynamicClient, mapping, result.Name, result.Namespace)
+		}
+		waitResult.Err = err
+		waits = append(waits, waitResult)
+	}
+	return waits
+}
+
+func waitForMapping(ctx context.Context, dynamicClient dynamic.Interface, mapping *meta.RESTMapping, name string, namespace string) error {
+	namespace = scopedNamespace(mapping, namespace)
+	resource := mapping.Resource
+
+	var check readinessCheck
+	switch mapping.GroupVersionKind.GroupKind() {
+	case schema.GroupKind{Group: "apps", Kind: "Deployment"}:
+		check = deploymentReady
+	case schema.GroupKind{Group: "batch", Kind: "Job"}:
+		check = jobComplete
+	case schema.GroupKind{Kind: "Pod"}:
+		check = podReady
+	case schema.GroupKind{Kind: "Service"}:
+		service, err := dynamicClient.Resource(resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		// Services without a selector get their endpoints from elsewhere, if
+		// at all, so like ExternalName services they are ready once they exist.
+		serviceType, _, _ := unstructured.NestedString(service.Object, "spec", "type")
+		selector, _, _ := unstructured.NestedStringMap(service.Object, "spec", "selector")
+		if serviceType == string(corev1.ServiceTypeExternalName) || len(selector) == 0 {
+			return nil
+		}
+		// Endpoints share the name of their service.
+		resource, check = endpointsGVR, hasEndpoints
+	default:
+		check = func(*unstructured.Unstructured) (bool, error) { return true, nil }
+	}
+
+	return watchUntil(ctx, dynamicClient, resource, namespace, name, check)
+}
+
+// watchUntil watches a single object until check is satisfied. The object
+// is listed first, so an object that is already ready returns at once.
+func watchUntil(ctx context.Context, dynamicClient dynamic.Interface, resource schema.GroupVersionResource, namespace string, name string, check readinessCheck) error {
+	var client dynamic.ResourceInterface = dynamicClient.Resource(resource)
+	if namespace != "" {
+		client = dynamicClient.Resource(resource).Namespace(namespace)
+	}
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return client.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return client.Watch(ctx, options)
+		},
+	}
+
+	_, err := watchtools.UntilWithSync(ctx, listWatch, &unstructured.Unstructured{}, nil, func(event watch.Event) (bool, error) {
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok || obj.GetName() != name || event.Type == watch.Deleted {
+			return false, nil
+		}
+		return check(obj)
+	})
+	if wait.Interrupted(err) {
+		return fmt.Errorf("%w for %s %s", ErrWaitTimeout, resource.Resource, name)
+	}
+	return err
+}
+
+func scopedNamespace(mapping *meta.RESTMapping, namespace string) string {
+	if !isNamespaced(mapping) {
+		return ""
+	}
+	if namespace == "" {
+		return corev1.NamespaceDefault
+	}
+	return namespace
+}
+
+func deploymentReady(obj *unstructured.Unstructured) (bool, error) {
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+
+	return observedGeneration >= obj.GetGeneration() && updated >= replicas && available >= replicas, nil
+}
+
+func jobComplete(obj *unstructured.Unstructured) (bool, error) {
+	if status, _ := conditionStatusOf(obj, "Failed"); status == string(metav1.ConditionTrue) {
+		return false, fmt.Errorf("%w: job %s failed", ErrNotReady, obj.GetName())
+	}
+	status, _ := conditionStatusOf(obj, "Complete")
+	return status == string(metav1.ConditionTrue), nil
+}
+
+func podReady(obj *unstructured.Unstructured) (bool, error) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch corev1.PodPhase(phase) {
+	case corev1.PodSucceeded:
+		return true, nil
+	case corev1.PodFailed:
+		return false, fmt.Errorf("%w: pod %s failed", ErrNotReady, obj.GetName())
+	}
+	status, _ := conditionStatusOf(obj, string(corev1.PodReady))
+	return status == string(corev1.ConditionTrue), nil
+}
+
+func hasEndpoints(obj *unstructured.Unstructured) (bool, error) {
+	subsets, _, _ := unstructured.NestedSlice(obj.Object, "subsets")
+	for _, subset := range subsets {
+		addresses, _, _ := unstructured.NestedSlice(subset.(map[string]interface{}), "addresses")
+		if len(addresses) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// conditionStatusOf returns the status of the condition of conditionType.
+func conditionStatusOf(obj *unstructured.Unstructured, conditionType string) (string, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		fields, ok := condition.(map[string]interface{})
+		if !ok || fields["type"] != conditionType {
+			continue
+		}
+		status, _ := fields["status"].(string)
+		return status, true
+	}
+	return "", false
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func withConditions(obj *unstructured.Unstructured, conditions map[string]string) *unstructured.Unstructured {
+	var list []interface{}
+	for conditionType, status := range conditions {
+		list = append(list, map[string]interface{}{"type": conditionType, "status": status})
+	}
+	unstructured.SetNestedSlice(obj.Object, list, "status", "conditions")
+	return obj
+}
+
+func newDeployment(name string, replicas, available int64) *unstructured.Unstructured {
+	deployment := newObject("apps/v1", "Deployment", name, "default", nil)
+	deployment.SetGeneration(1)
+	unstructured.SetNestedField(deployment.Object, replicas, "spec", "replicas")
+	unstructured.SetNestedField(deployment.Object, int64(1), "status", "observedGeneration")
+	unstructured.SetNestedField(deployment.Object, replicas, "status", "updatedReplicas")
+	unstructured.SetNestedField(deployment.Object, available, "status", "availableReplicas")
+	return deployment
+}
+
+func TestReadinessChecks(t *testing.T) {
+	endpoints := newObject("v1", "Endpoints", "web", "default", nil)
+	unstructured.SetNestedSlice(endpoints.Object, []interface{}{
+		map[string]interface{}{"addresses": []interface{}{map[string]interface{}{"ip": "10.0.0.1"}}},
+	}, "subsets")
+	succeeded := newObject("v1", "Pod", "done", "default", nil)
+	unstructured.SetNestedField(succeeded.Object, "Succeeded", "status", "phase")
+
+	tests := []struct {
+		name  string
+		check readinessCheck
+		obj   *unstructured.Unstructured
+		ready bool
+	}{
+		{"available deployment", deploymentReady, newDeployment("web", 2, 2), true},
+		{"rolling deployment", deploymentReady, newDeployment("web", 2, 1), false},
+		{"complete job", jobComplete, withConditions(newObject("batch/v1", "Job", "migrate", "default", nil), map[string]string{"Complete": "True"}), true},
+		{"running job", jobComplete, newObject("batch/v1", "Job", "migrate", "default", nil), false},
+		{"ready pod", podReady, withConditions(newObject("v1", "Pod", "web", "default", nil), map[string]string{"Ready": "True"}), true},
+		{"unready pod", podReady, withConditions(newObject("v1", "Pod", "web", "default", nil), map[string]string{"Ready": "False"}), false},
+		{"succeeded pod", podReady, succeeded, true},
+		{"endpoints with addresses", hasEndpoints, endpoints, true},
+		{"empty endpoints", hasEndpoints, newObject("v1", "Endpoints", "web", "default", nil), false},
+	}
+	for _, tt := range tests {
+		ready, err := tt.check(tt.obj)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if ready != tt.ready {
+			t.Errorf("%s: expected ready %v, got %v", tt.name, tt.ready, ready)
+		}
+	}
+
+	failed := withConditions(newObject("batch/v1", "Job", "migrate", "default", nil), map[string]string{"Failed": "True"})
+	if _, err := jobComplete(failed); !errors.Is(err, ErrNotReady) {
+		t.Errorf("expected ErrNotReady for a failed job, got %v", err)
+	}
+}
+
+func TestWaitForReadyWatchesUntilReady(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newDeployment("web", 2, 0))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- WaitForReady(ctx, dynamicClient, newTestMapper(), "deployment", "web", "default")
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := dynamicClient.Resource(deploymentsGVR).Namespace("default").Update(context.TODO(), newDeployment("web", 2, 2), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("updating deployment: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("WaitForReady returned error: %v", err)
+	}
+}
+
+func TestWaitForReadyTimeout(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newObject("batch/v1", "Job", "migrate", "default", nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := WaitForReady(ctx, dynamicClient, newTestMapper(), "job", "migrate", "default"); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("expected ErrWaitTimeout, got %v", err)
+	}
+}
+
+func TestWaitForCondition(t *testing.T) {
+	deployment := withConditions(newDeployment("web", 1, 1), map[string]string{"Available": "True", "Progressing": "False"})
+	dynamicClient := newFakeDynamicClient(deployment)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := WaitForCondition(ctx, dynamicClient, newTestMapper(), "deployments", "web", "default", "Available", ""); err != nil {
+		t.Errorf("expected Available to be met, got %v", err)
+	}
+	if err := WaitForCondition(ctx, dynamicClient, newTestMapper(), "deployments", "web", "default", "Progressing", "False"); err != nil {
+		t.Errorf("expected Progressing=False to be met, got %v", err)
+	}
+	if err := WaitForCondition(ctx, dynamicClient, newTestMapper(), "deployments", "web", "default", "Paused", ""); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("expected ErrWaitTimeout for a missing condition, got %v", err)
+	}
+}
+
+func TestWaitForCreated(t *testing.T) {
+	service := newObject("v1", "Service", "web", "default", nil)
+	unstructured.SetNestedStringMap(service.Object, map[string]string{"app": "web"}, "spec", "selector")
+	// Without a selector and without endpoints, db is ready once it exists.
+	external := newObject("v1", "Service", "db", "default", nil)
+	endpoints := newObject("v1", "Endpoints", "web", "default", nil)
+	unstructured.SetNestedSlice(endpoints.Object, []interface{}{
+		map[string]interface{}{"addresses": []interface{}{map[string]interface{}{"ip": "10.0.0.1"}}},
+	}, "subsets")
+	job := withConditions(newObject("batch/v1", "Job", "migrate", "default", nil), map[string]string{"Complete": "True"})
+	dynamicClient := newFakeDynamicClient(service, external, endpoints, job)
+
+	results := []CreateResult{
+		{Kind: "Service", Name: "web", Namespace: "default", Object: service},
+		{Kind: "Service", Name: "db", Namespace: "default", Object: external},
+		{Kind: "Job", Name: "migrate", Namespace: "default", Object: job},
+		{Kind: "ConfigMap", Name: "broken", Namespace: "default", Object: newConfigMap("broken", "default"), Err: errors.New("create failed")},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	waits := WaitForCreated(ctx, dynamicClient, newTestMapper(), results)
+	if len(waits) != 3 {
+		t.Fatalf("expected to wait for the 3 created objects, got %+v", waits)
+	}
+	for _, waitResult := range waits {
+		if waitResult.Err != nil {
+			t.Errorf("waiting for %s %s: %v", waitResult.Kind, waitResult.Name, waitResult.Err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// FieldManager is the field manager kuba applies objects with.
+const FieldManager = "kuba"
+
+// Actions reported for applied objects.
+const (
+	ApplyCreated    = "created"
+	ApplyConfigured = "configured"
+	ApplyUnchanged  = "unchanged"
+)
+
+// ApplyResult is the outcome of applying a single object from a manifest.
+type ApplyResult struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Action    string
+	Object    *unstructured.Unstructured
+	Err       error
+}
+
+// YamlResourceApplier applies every object of a manifest with server-side
+// apply, so running it again with the same manifest changes nothing. With
+// forceConflicts kuba takes over fields owned by other field managers.
+func YamlResourceApplier(dynamicClient dynamic.Interface, mapper meta.RESTMapper, namespace string, manifests ManifestOptions, forceConflicts bool) ([]ApplyResult, error) {
+	objects, err := readManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ApplyResult
+	for _, obj := range objects {
+		results = append(results, applyObject(dynamicClient, mapper, namespace, obj, forceConflicts))
+	}
+
+	return results, nil
+}
+
+// applyObject applies obj and tells created, configured and unchanged
+// objects apart by comparing the resourceVersion before and after.
+func applyObject(dynamicClient dynamic.Interface, mapper meta.RESTMapper, namespace string, obj *unstructured.Unstructured, forceConflicts bool) ApplyResult {
+	result := ApplyResult{Kind: obj.GetKind(), Name: obj.GetName(), Object: obj}
+
+	mapping, err := scopeObject(mapper, namespace, obj)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Namespace = obj.GetNamespace()
+	resource := resourceInterface(dynamicClient, mapping, obj.GetNamespace())
+
+	live, err := resource.Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		result.Err = err
+		return result
+	}
+
+	applied, err := resource.Apply(context.TODO(), obj.GetName(), obj, metav1.ApplyOptions{FieldManager: FieldManager, Force: forceConflicts})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	switch {
+	case live == nil:
+		result.Action = ApplyCreated
+	case live.GetResourceVersion() == applied.GetResourceVersion():
+		result.Action = ApplyUnchanged
+	default:
+		result.Action = ApplyConfigured
+	}
+	return result
+}
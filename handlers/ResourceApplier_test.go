@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// addApplyReactor makes the fake client handle server-side apply like the
+// API server does for these tests: the applied object replaces the stored
+// one and the resourceVersion only changes when the object does.
+func addApplyReactor(client *dynamicfake.FakeDynamicClient) {
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+
+		applied := &unstructured.Unstructured{}
+		if err := applied.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+
+		tracker := client.Tracker()
+		existing, err := tracker.Get(action.GetResource(), action.GetNamespace(), patch.GetName())
+		if apierrors.IsNotFound(err) {
+			applied.SetResourceVersion("1")
+			return true, applied, tracker.Create(action.GetResource(), applied, action.GetNamespace())
+		}
+		if err != nil {
+			return true, nil, err
+		}
+
+		live := existing.(*unstructured.Unstructured)
+		applied.SetResourceVersion(live.GetResourceVersion())
+		if equality.Semantic.DeepEqual(live.Object, applied.Object) {
+			return true, live, nil
+		}
+		version, _ := strconv.Atoi(live.GetResourceVersion())
+		applied.SetResourceVersion(strconv.Itoa(version + 1))
+		return true, applied, tracker.Update(action.GetResource(), applied, action.GetNamespace())
+	})
+}
+
+func TestYamlResourceApplier(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fresh
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+  namespace: default
+data:
+  key: new
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+  namespace: default
+data:
+  key: value
+`)
+	changed := newConfigMap("changed", "default")
+	changed.SetResourceVersion("1")
+	unstructured.SetNestedField(changed.Object, "old", "data", "key")
+	same := newConfigMap("same", "default")
+	same.SetResourceVersion("1")
+	unstructured.SetNestedField(same.Object, "value", "data", "key")
+
+	dynamicClient := newFakeDynamicClient(changed, same)
+	addApplyReactor(dynamicClient)
+
+	results, err := YamlResourceApplier(dynamicClient, newTestMapper(), "", manifestPaths(path), false)
+	if err != nil {
+		t.Fatalf("YamlResourceApplier returned error: %v", err)
+	}
+
+	expected := map[string]string{"fresh": ApplyCreated, "changed": ApplyConfigured, "same": ApplyUnchanged}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %+v", len(expected), results)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("applying %s failed: %v", result.Name, result.Err)
+		}
+		if result.Action != expected[result.Name] {
+			t.Errorf("expected %s to be %s, got %q", result.Name, expected[result.Name], result.Action)
+		}
+		if result.Namespace != "default" {
+			t.Errorf("expected %s in namespace default, got %q", result.Name, result.Namespace)
+		}
+	}
+
+	live, err := dynamicClient.Resource(configMapsGVR).Namespace("default").Get(context.TODO(), "changed", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting config map: %v", err)
+	}
+	if value, _, _ := unstructured.NestedString(live.Object, "data", "key"); value != "new" {
+		t.Errorf("expected the applied data, got %q", value)
+	}
+}
+
+func TestYamlResourceApplierIsIdempotent(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  key: value
+`)
+	dynamicClient := newFakeDynamicClient()
+	addApplyReactor(dynamicClient)
+
+	for i, action := range []string{ApplyCreated, ApplyUnchanged} {
+		results, err := YamlResourceApplier(dynamicClient, newTestMapper(), "default", manifestPaths(path), false)
+		if err != nil {
+			t.Fatalf("apply %d returned error: %v", i+1, err)
+		}
+		if len(results) != 1 || results[0].Action != action {
+			t.Errorf("apply %d: expected %s, got %+v", i+1, action, results)
+		}
+	}
+}
+
+func TestYamlResourceApplierReportsConflicts(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: owned
+data:
+  key: value
+`)
+	dynamicClient := newFakeDynamicClient(newConfigMap("owned", "default"))
+	dynamicClient.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(configMapsGVR.GroupResource(), "owned", nil)
+	})
+
+	results, err := YamlResourceApplier(dynamicClient, newTestMapper(), "default", manifestPaths(path), false)
+	if err != nil {
+		t.Fatalf("YamlResourceApplier returned error: %v", err)
+	}
+	if len(results) != 1 || !apierrors.IsConflict(results[0].Err) {
+		t.Errorf("expected a conflict, got %+v", results)
+	}
+}
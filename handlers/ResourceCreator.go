@@ -1,142 +1,206 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
-	"io/ioutil"
+	"io"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/scheme"
-
-	appsv1 "k8s.io/api/apps/v1"
-	batchv1 "k8s.io/api/batch/v1"
-	batchv1beta1 "k8s.io/api/batch/v1beta1"
-	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
 )
 
-func YamlResourceCreator(clientset *kubernetes.Clientset, namespace string, filePath string) error {
-	yamlContent, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
+// CreateResult is the outcome of creating a single object from a manifest.
+// Object is the object as it was sent, or would be sent on a client dry run.
+type CreateResult struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Object    *unstructured.Unstructured
+	Err       error
+}
 
-	decoder := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer()
+// kindOrder lists kinds in the order they have to exist in the cluster:
+// namespaces first, then configuration, then the workloads that consume it.
+// Kinds not listed here, including custom resources, are created last so
+// that their CustomResourceDefinitions exist by then.
+var kindOrder = []string{
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+}
 
-	obj, _, err := decoder.Decode(yamlContent, nil, nil)
-	if err != nil {
-		return err
+func kindPriority(kind string) int {
+	for i, k := range kindOrder {
+		if k == kind {
+			return i
+		}
 	}
+	return len(kindOrder)
+}
 
-	typedObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+// YamlResourceCreator creates every object of a manifest. On a client dry
+// run objects are only resolved against the cluster's kinds, on a server dry
+// run the API server validates them without persisting anything.
+func YamlResourceCreator(dynamicClient dynamic.Interface, mapper meta.RESTMapper, namespace string, manifests ManifestOptions, dryRun DryRunStrategy) ([]CreateResult, error) {
+	objects, err := readManifests(manifests)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	switch kind := typedObj["kind"]; kind {
-	case "Deployment":
-		deployment := &appsv1.Deployment{}
-		err := runtime.DefaultUnstructuredConverter.FromUnstructured(typedObj, deployment)
-		if err != nil {
-			return err
-		}
+	var results []CreateResult
+	for _, obj := range objects {
+		results = append(results, createObject(dynamicClient, mapper, namespace, obj, dryRun))
+	}
 
-		_, err = clientset.AppsV1().Deployments(namespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
-		if err != nil {
-			return err
-		}
+	return results, nil
+}
 
-		fmt.Println("Deployment created:", deployment.GetName())
+// YamlResourceCreatorAtomic creates the objects of a manifest like
+// YamlResourceCreator but stops at the first failure, which is the last
+// result. Objects after it are not attempted, so RollbackCreated can undo
+// the run.
+func YamlResourceCreatorAtomic(dynamicClient dynamic.Interface, mapper meta.RESTMapper, namespace string, manifests ManifestOptions, dryRun DryRunStrategy) ([]CreateResult, error) {
+	objects, err := readManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
 
-	case "Service":
-		service := &corev1.Service{}
-		err := runtime.DefaultUnstructuredConverter.FromUnstructured(typedObj, service)
-		if err != nil {
-			return err
+	var results []CreateResult
+	for _, obj := range objects {
+		result := createObject(dynamicClient, mapper, namespace, obj, dryRun)
+		results = append(results, result)
+		if result.Err != nil {
+			break
 		}
+	}
 
-		_, err = clientset.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
-		if err != nil {
-			return err
-		}
+	return results, nil
+}
 
-		fmt.Println("Service created:", service.GetName())
+// decodeDocuments splits a manifest on "---" and decodes every non-empty
+// document into an unstructured object, so that any kind can be handled.
+func decodeDocuments(content []byte) ([]*unstructured.Unstructured, error) {
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
 
-	case "StatefulSet":
-		statefulSet := &appsv1.StatefulSet{}
-		err := runtime.DefaultUnstructuredConverter.FromUnstructured(typedObj, statefulSet)
-		if err != nil {
-			return err
+	var objects []*unstructured.Unstructured
+	for i := 1; ; i++ {
+		document, err := reader.Read()
+		if err == io.EOF {
+			break
 		}
-
-		_, err = clientset.AppsV1().StatefulSets(namespace).Create(context.TODO(), statefulSet, metav1.CreateOptions{})
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		fmt.Println("StatefulSet created:", statefulSet.GetName())
-
-	case "DaemonSet":
-		daemonSet := &appsv1.DaemonSet{}
-		err := runtime.DefaultUnstructuredConverter.FromUnstructured(typedObj, daemonSet)
+		jsonDocument, err := yaml.ToJSON(document)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("document %d: %w", i, err)
 		}
-
-		_, err = clientset.AppsV1().DaemonSets(namespace).Create(context.TODO(), daemonSet, metav1.CreateOptions{})
-		if err != nil {
-			return err
+		jsonDocument = bytes.TrimSpace(jsonDocument)
+		if len(jsonDocument) == 0 || string(jsonDocument) == "null" {
+			continue
 		}
 
-		fmt.Println("DaemonSet created:", daemonSet.GetName())
-
-	case "Job":
-		job := &batchv1.Job{}
-		err := runtime.DefaultUnstructuredConverter.FromUnstructured(typedObj, job)
-		if err != nil {
-			return err
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(jsonDocument); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
 		}
-
-		_, err = clientset.BatchV1().Jobs(namespace).Create(context.TODO(), job, metav1.CreateOptions{})
-		if err != nil {
-			return err
+		if obj.GetAPIVersion() == "" {
+			return nil, fmt.Errorf("document %d: apiVersion must be set", i)
 		}
 
-		fmt.Println("Job created:", job.GetName())
-
-	case "CronJob":
-		cronJob := &batchv1beta1.CronJob{}
-		err := runtime.DefaultUnstructuredConverter.FromUnstructured(typedObj, cronJob)
-		if err != nil {
-			return err
+		// Lists, such as the output of "kuba show -o json --raw", are
+		// expanded into their items.
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				objects = append(objects, item.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("document %d: %w", i, err)
+			}
+			continue
 		}
 
-		_, err = clientset.BatchV1beta1().CronJobs(namespace).Create(context.TODO(), cronJob, metav1.CreateOptions{})
-		if err != nil {
-			return err
-		}
+		objects = append(objects, obj)
+	}
 
-		fmt.Println("CronJob created:", cronJob.GetName())
+	return objects, nil
+}
 
-	case "Namespace":
-		namespaceObj := &corev1.Namespace{}
-		err := runtime.DefaultUnstructuredConverter.FromUnstructured(typedObj, namespaceObj)
-		if err != nil {
-			return err
-		}
+func createObject(dynamicClient dynamic.Interface, mapper meta.RESTMapper, namespace string, obj *unstructured.Unstructured, dryRun DryRunStrategy) CreateResult {
+	result := CreateResult{Kind: obj.GetKind(), Name: obj.GetName(), Object: obj}
 
-		_, err = clientset.CoreV1().Namespaces().Create(context.TODO(), namespaceObj, metav1.CreateOptions{})
-		if err != nil {
-			return err
-		}
+	mapping, err := scopeObject(mapper, namespace, obj)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Namespace = obj.GetNamespace()
+	if dryRun == DryRunClient {
+		return result
+	}
 
-		fmt.Println("Namespace created:", namespaceObj.GetName())
+	_, result.Err = resourceInterface(dynamicClient, mapping, obj.GetNamespace()).Create(context.TODO(), obj, metav1.CreateOptions{DryRun: dryRun.serverDryRun()})
+	return result
+}
 
-	default:
-		return fmt.Errorf("unsupported kind: %s", kind)
+// scopeObject resolves the mapping of a manifest object and sets its
+// namespace: the flag fills in a namespace the manifest leaves out, falling
+// back to "default", and must match the one it names. Cluster-scoped
+// objects have their namespace cleared.
+func scopeObject(mapper meta.RESTMapper, namespace string, obj *unstructured.Unstructured) (*meta.RESTMapping, error) {
+	mapping, err := mappingFor(mapper, obj.GroupVersionKind())
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	if isNamespaced(mapping) {
+		manifestNamespace := obj.GetNamespace()
+		if namespace != "" && manifestNamespace != "" && namespace != manifestNamespace {
+			return nil, fmt.Errorf("%w: the namespace of %s %s is %q, which does not match --ns=%s",
+				ErrInvalidManifest, obj.GetKind(), obj.GetName(), manifestNamespace, namespace)
+		}
+		if namespace == "" {
+			namespace = manifestNamespace
+		}
+		if namespace == "" {
+			namespace = corev1.NamespaceDefault
+		}
+		obj.SetNamespace(namespace)
+	} else {
+		obj.SetNamespace("")
+	}
+	return mapping, nil
 }
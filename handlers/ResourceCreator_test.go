@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	servicesGVR    = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	configMapsGVR  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	namespacesGVR  = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	widgetsGVR     = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+)
+
+func newConfigMap(name, namespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return obj
+}
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing manifest: %v", err)
+	}
+	return path
+}
+
+func TestYamlResourceCreatorMultiDocument(t *testing.T) {
+	dynamicClient := newFakeDynamicClient()
+
+	results, err := YamlResourceCreator(dynamicClient, newTestMapper(), "default", manifestPaths("../TestYamls/testDeployment.yaml"), DryRunNone)
+	if err != nil {
+		t.Fatalf("YamlResourceCreator returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d: %+v", len(results), results)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("creating %s %s failed: %v", result.Kind, result.Name, result.Err)
+		}
+	}
+
+	if _, err := dynamicClient.Resource(deploymentsGVR).Namespace("default").Get(context.TODO(), "test-deployment", metav1.GetOptions{}); err != nil {
+		t.Errorf("deployment was not created: %v", err)
+	}
+	if _, err := dynamicClient.Resource(servicesGVR).Namespace("default").Get(context.TODO(), "test-service", metav1.GetOptions{}); err != nil {
+		t.Errorf("service was not created: %v", err)
+	}
+}
+
+func TestYamlResourceCreatorDependencyOrder(t *testing.T) {
+	path := writeManifest(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+        - name: web
+          image: nginx
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  key: value
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: team-a
+`)
+	dynamicClient := newFakeDynamicClient()
+
+	results, err := YamlResourceCreator(dynamicClient, newTestMapper(), "team-a", manifestPaths(path), DryRunNone)
+	if err != nil {
+		t.Fatalf("YamlResourceCreator returned error: %v", err)
+	}
+
+	var kinds []string
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("creating %s %s failed: %v", result.Kind, result.Name, result.Err)
+		}
+		kinds = append(kinds, result.Kind)
+	}
+	want := []string{"Namespace", "ConfigMap", "Deployment"}
+	if len(kinds) != len(want) {
+		t.Fatalf("expected kinds %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("expected kinds %v, got %v", want, kinds)
+			break
+		}
+	}
+}
+
+func TestYamlResourceCreatorNamespaceFallback(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: from-manifest
+  namespace: team-a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: no-namespace
+`)
+	dynamicClient := newFakeDynamicClient()
+
+	results, err := YamlResourceCreator(dynamicClient, newTestMapper(), "", manifestPaths(path), DryRunNone)
+	if err != nil {
+		t.Fatalf("YamlResourceCreator returned error: %v", err)
+	}
+	if results[0].Namespace != "team-a" || results[1].Namespace != corev1.NamespaceDefault {
+		t.Errorf("unexpected namespaces: %+v", results)
+	}
+}
+
+func TestYamlResourceCreatorNamespaceMismatch(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: from-manifest
+  namespace: team-a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: no-namespace
+`)
+	dynamicClient := newFakeDynamicClient()
+
+	results, err := YamlResourceCreator(dynamicClient, newTestMapper(), "team-b", manifestPaths(path), DryRunNone)
+	if err != nil {
+		t.Fatalf("YamlResourceCreator returned error: %v", err)
+	}
+	if !errors.Is(results[0].Err, ErrInvalidManifest) {
+		t.Errorf("expected the namespace mismatch to be refused, got %+v", results[0])
+	}
+	if _, err := dynamicClient.Resource(configMapsGVR).Namespace("team-b").Get(context.TODO(), "from-manifest", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected from-manifest not to be moved to team-b, got %v", err)
+	}
+	if results[1].Err != nil || results[1].Namespace != "team-b" {
+		t.Errorf("expected the flag to fill in the missing namespace, got %+v", results[1])
+	}
+}
+
+func TestYamlResourceCreatorReportsFailures(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: existing
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fresh
+`)
+	dynamicClient := newFakeDynamicClient(newConfigMap("existing", "default"))
+
+	results, err := YamlResourceCreator(dynamicClient, newTestMapper(), "default", manifestPaths(path), DryRunNone)
+	if err != nil {
+		t.Fatalf("YamlResourceCreator returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if !apierrors.IsAlreadyExists(results[0].Err) {
+		t.Errorf("expected AlreadyExists for the existing config map, got %v", results[0].Err)
+	}
+	if results[1].Err != nil {
+		t.Errorf("expected the second config map to be created, got %v", results[1].Err)
+	}
+}
+
+func TestYamlResourceCreatorAnyKind(t *testing.T) {
+	path := writeManifest(t, `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gizmo
+spec:
+  size: 3
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: reader
+`)
+	dynamicClient := newFakeDynamicClient()
+
+	results, err := YamlResourceCreator(dynamicClient, newTestMapper(), "team-a", manifestPaths(path), DryRunNone)
+	if err != nil {
+		t.Fatalf("YamlResourceCreator returned error: %v", err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("creating %s %s failed: %v", result.Kind, result.Name, result.Err)
+		}
+		if result.Kind == "ClusterRole" && result.Namespace != "" {
+			t.Errorf("expected the cluster-scoped ClusterRole to have no namespace, got %q", result.Namespace)
+		}
+	}
+
+	widget, err := dynamicClient.Resource(widgetsGVR).Namespace("team-a").Get(context.TODO(), "gizmo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("custom resource was not created: %v", err)
+	}
+	if size, _, _ := unstructured.NestedInt64(widget.Object, "spec", "size"); size != 3 {
+		t.Errorf("expected spec.size 3, got %d", size)
+	}
+}
+
+func TestYamlResourceCreatorUnsupportedKind(t *testing.T) {
+	path := writeManifest(t, `apiVersion: example.com/v1
+kind: Gadget
+metadata:
+  name: unknown
+`)
+	dynamicClient := newFakeDynamicClient()
+
+	results, err := YamlResourceCreator(dynamicClient, newTestMapper(), "default", manifestPaths(path), DryRunNone)
+	if err != nil {
+		t.Fatalf("YamlResourceCreator returned error: %v", err)
+	}
+	if len(results) != 1 || !errors.Is(results[0].Err, ErrUnsupportedKind) {
+		t.Errorf("expected an unsupported kind failure, got %+v", results)
+	}
+}
+
+func TestYamlResourceCreatorInvalidManifest(t *testing.T) {
+	path := writeManifest(t, "kind: [this is not a manifest")
+
+	if _, err := YamlResourceCreator(newFakeDynamicClient(), newTestMapper(), "default", manifestPaths(path), DryRunNone); !errors.Is(err, ErrInvalidManifest) {
+		t.Fatalf("expected ErrInvalidManifest, got %v", err)
+	}
+}
+
+func TestYamlResourceCreatorMissingKind(t *testing.T) {
+	path := writeManifest(t, `metadata:
+  name: nameless
+`)
+
+	if _, err := YamlResourceCreator(newFakeDynamicClient(), newTestMapper(), "default", manifestPaths(path), DryRunNone); !errors.Is(err, ErrInvalidManifest) {
+		t.Fatalf("expected ErrInvalidManifest for a document without apiVersion and kind, got %v", err)
+	}
+}
+
+func TestYamlResourceCreatorMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+
+	if _, err := YamlResourceCreator(newFakeDynamicClient(), newTestMapper(), "default", manifestPaths(path), DryRunNone); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
+
+func TestYamlResourceCreatorClientDryRun(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: preview
+---
+apiVersion: example.com/v1
+kind: Gadget
+metadata:
+  name: unknown
+`)
+	dynamicClient := newFakeDynamicClient()
+
+	results, err := YamlResourceCreator(dynamicClient, newTestMapper(), "team-a", manifestPaths(path), DryRunClient)
+	if err != nil {
+		t.Fatalf("YamlResourceCreator returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if results[0].Err != nil || results[0].Object == nil || results[0].Object.GetNamespace() != "team-a" {
+		t.Errorf("expected the config map to be validated and scoped to team-a, got %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, ErrUnsupportedKind) {
+		t.Errorf("expected the unsupported kind to fail validation, got %v", results[1].Err)
+	}
+	if len(dynamicClient.Actions()) != 0 {
+		t.Errorf("expected no requests on a client dry run, got %v", dynamicClient.Actions())
+	}
+}
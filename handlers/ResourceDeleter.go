@@ -2,74 +2,102 @@ package handlers
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"slices"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/dynamic"
 )
 
-func ResourceDelete(clientset *kubernetes.Clientset, kind string, name string, namespace string) error {
-	kind = strings.ToLower(kind)
-
-	switch kind {
-	case "deployment":
-		err := clientset.AppsV1().Deployments(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
-			return err
-		}
+// DeleteResult is the outcome of deleting a single object.
+type DeleteResult struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Err       error
+}
 
-	case "service":
-		err := clientset.CoreV1().Services(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
-			return err
-		}
+// ResourceDelete deletes a single object of any kind served by the cluster.
+// The namespace is ignored for cluster-scoped kinds. A client dry run only
+// checks that the object exists.
+func ResourceDelete(dynamicClient dynamic.Interface, mapper meta.RESTMapper, kind string, name string, namespace string, dryRun DryRunStrategy) error {
+	mapping, err := resolveKind(mapper, kind)
+	if err != nil {
+		return err
+	}
 
-	case "namespace":
-		err := clientset.CoreV1().Namespaces().Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
-			return err
-		}
+	resource := resourceInterface(dynamicClient, mapping, namespace)
+	if dryRun == DryRunClient {
+		_, err := resource.Get(context.TODO(), name, metav1.GetOptions{})
+		return err
+	}
+	return resource.Delete(context.TODO(), name, metav1.DeleteOptions{DryRun: dryRun.serverDryRun()})
+}
 
-	case "configmap":
-		err := clientset.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
-			return err
-		}
+// ResourceSelect lists the objects of a kind that match a label selector.
+// An empty selector matches every object of that kind in the namespace.
+func ResourceSelect(dynamicClient dynamic.Interface, mapper meta.RESTMapper, kind string, namespace string, selector string) ([]ResourceInfo, error) {
+	mapping, err := resolveKind(mapper, kind)
+	if err != nil {
+		return nil, err
+	}
 
-	case "secret":
-		err := clientset.CoreV1().Secrets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
-			return err
-		}
+	list, err := resourceInterface(dynamicClient, mapping, namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
 
-	case "statefulset":
-		err := clientset.AppsV1().StatefulSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
-			return err
-		}
+	var resources []ResourceInfo
+	for _, item := range list.Items {
+		resources = append(resources, ResourceInfo{
+			Kind:      mapping.GroupVersionKind.Kind,
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			CreatedAt: item.GetCreationTimestamp().Time,
+		})
+	}
+	return resources, nil
+}
 
-	case "daemonset":
-		err := clientset.AppsV1().DaemonSets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
-			return err
-		}
+// SystemNamespaces are the namespaces Kubernetes creates itself, which bulk
+// deletes leave alone.
+var SystemNamespaces = []string{"default", "kube-system", "kube-public", "kube-node-lease"}
 
-	case "job":
-		err := clientset.BatchV1().Jobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
-			return err
-		}
+// IsClusterScoped reports whether the objects of kind live outside of
+// namespaces, such as namespaces, nodes or cluster roles.
+func IsClusterScoped(mapper meta.RESTMapper, kind string) (bool, error) {
+	mapping, err := resolveKind(mapper, kind)
+	if err != nil {
+		return false, err
+	}
+	return !isNamespaced(mapping), nil
+}
 
-	case "cronjob":
-		err := clientset.BatchV1beta1().CronJobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
-		if err != nil {
-			return err
+// WithoutSystemNamespaces splits the SystemNamespaces off resources.
+func WithoutSystemNamespaces(resources []ResourceInfo) ([]ResourceInfo, []ResourceInfo) {
+	var kept, skipped []ResourceInfo
+	for _, resource := range resources {
+		if resource.Kind == "Namespace" && slices.Contains(SystemNamespaces, resource.Name) {
+			skipped = append(skipped, resource)
+			continue
 		}
-	default:
-		return fmt.Errorf("unsupported resource kind: %s", kind)
+		kept = append(kept, resource)
 	}
+	return kept, skipped
+}
 
-	fmt.Printf("Resource deleted: kind=%s, name=%s, namespace=%s\n", kind, name, namespace)
-	return nil
+// ResourceDeleteAll deletes every listed object and reports the outcome of
+// each one instead of stopping at the first failure.
+func ResourceDeleteAll(dynamicClient dynamic.Interface, mapper meta.RESTMapper, resources []ResourceInfo, dryRun DryRunStrategy) []DeleteResult {
+	var results []DeleteResult
+	for _, resource := range resources {
+		err := ResourceDelete(dynamicClient, mapper, resource.Kind, resource.Name, resource.Namespace, dryRun)
+		results = append(results, DeleteResult{
+			Kind:      resource.Kind,
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+			Err:       err,
+		})
+	}
+	return results
 }
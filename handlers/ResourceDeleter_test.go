@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObject(apiVersion, kind, name, namespace string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestResourceDelete(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(
+		newObject("apps/v1", "Deployment", "web", "default", nil),
+		newConfigMap("web-config", "default"),
+		newObject("v1", "Namespace", "team-a", "", nil),
+		newObject("example.com/v1", "Widget", "gizmo", "default", nil),
+	)
+
+	tests := []struct {
+		kind string
+		name string
+	}{
+		{kind: "Deployment", name: "web"},
+		{kind: "configmap", name: "web-config"},
+		{kind: "namespace", name: "team-a"},
+		{kind: "widgets", name: "gizmo"},
+	}
+	for _, tt := range tests {
+		if err := ResourceDelete(dynamicClient, newTestMapper(), tt.kind, tt.name, "default", DryRunNone); err != nil {
+			t.Errorf("deleting %s %s: %v", tt.kind, tt.name, err)
+		}
+	}
+
+	if _, err := dynamicClient.Resource(deploymentsGVR).Namespace("default").Get(context.TODO(), "web", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the deployment to be gone, got %v", err)
+	}
+	if _, err := dynamicClient.Resource(namespacesGVR).Get(context.TODO(), "team-a", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the namespace to be gone, got %v", err)
+	}
+}
+
+func TestResourceDeleteNotFound(t *testing.T) {
+	err := ResourceDelete(newFakeDynamicClient(), newTestMapper(), "service", "missing", "default", DryRunNone)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+func TestResourceDeleteUnsupportedKind(t *testing.T) {
+	if err := ResourceDelete(newFakeDynamicClient(), newTestMapper(), "gadget", "web", "default", DryRunNone); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected ErrUnsupportedKind, got %v", err)
+	}
+}
+
+func TestResourceSelect(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(
+		newObject("apps/v1", "Deployment", "web", "default", map[string]string{"app": "test"}),
+		newObject("apps/v1", "Deployment", "api", "default", map[string]string{"app": "test"}),
+		newObject("apps/v1", "Deployment", "db", "default", map[string]string{"app": "db"}),
+		newObject("apps/v1", "Deployment", "other", "team-a", map[string]string{"app": "test"}),
+	)
+
+	resources, err := ResourceSelect(dynamicClient, newTestMapper(), "deployment", "default", "app=test")
+	if err != nil {
+		t.Fatalf("ResourceSelect returned error: %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("expected 2 matching deployments, got %+v", resources)
+	}
+	for _, resource := range resources {
+		if resource.Kind != "Deployment" || resource.Namespace != "default" || resource.Name == "db" {
+			t.Errorf("unexpected match: %+v", resource)
+		}
+	}
+
+	all, err := ResourceSelect(dynamicClient, newTestMapper(), "deployment", "default", "")
+	if err != nil {
+		t.Fatalf("ResourceSelect returned error: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected every deployment in the namespace, got %+v", all)
+	}
+}
+
+func TestResourceDeleteAll(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newConfigMap("web", "default"))
+	resources := []ResourceInfo{
+		{Kind: "ConfigMap", Name: "web", Namespace: "default"},
+		{Kind: "ConfigMap", Name: "gone", Namespace: "default"},
+	}
+
+	results := ResourceDeleteAll(dynamicClient, newTestMapper(), resources, DryRunNone)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Err != nil {
+		t.Errorf("expected web to be deleted, got %v", results[0].Err)
+	}
+	if !apierrors.IsNotFound(results[1].Err) {
+		t.Errorf("expected NotFound for gone, got %v", results[1].Err)
+	}
+	if _, err := dynamicClient.Resource(configMapsGVR).Namespace("default").Get(context.TODO(), "web", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the config map to be gone, got %v", err)
+	}
+}
+
+func TestSystemNamespacesAreSkipped(t *testing.T) {
+	for kind, want := range map[string]bool{"namespace": true, "clusterroles": true, "deployment": false} {
+		if clusterScoped, err := IsClusterScoped(newTestMapper(), kind); err != nil || clusterScoped != want {
+			t.Errorf("IsClusterScoped(%s) = %v, %v, want %v", kind, clusterScoped, err, want)
+		}
+	}
+
+	resources := []ResourceInfo{
+		{Kind: "Namespace", Name: "kube-system"},
+		{Kind: "Namespace", Name: "team-a"},
+		{Kind: "Namespace", Name: "default"},
+		{Kind: "ConfigMap", Name: "default", Namespace: "default"},
+	}
+	kept, skipped := WithoutSystemNamespaces(resources)
+	if len(kept) != 2 || kept[0].Name != "team-a" || kept[1].Kind != "ConfigMap" {
+		t.Errorf("expected team-a and the config map to be kept, got %+v", kept)
+	}
+	if len(skipped) != 2 {
+		t.Errorf("expected kube-system and default to be skipped, got %+v", skipped)
+	}
+}
+
+func TestResourceDeleteClientDryRun(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newConfigMap("web", "default"))
+
+	if err := ResourceDelete(dynamicClient, newTestMapper(), "configmap", "web", "default", DryRunClient); err != nil {
+		t.Fatalf("ResourceDelete returned error: %v", err)
+	}
+	if _, err := dynamicClient.Resource(configMapsGVR).Namespace("default").Get(context.TODO(), "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the config map to survive a client dry run, got %v", err)
+	}
+	if err := ResourceDelete(dynamicClient, newTestMapper(), "configmap", "missing", "default", DryRunClient); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound for a missing object on a client dry run, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// DiffResult is the difference between the live object and the object as
+// it would be after applying a manifest. Diff is a unified diff of the two
+// as YAML and is empty when applying would change nothing.
+type DiffResult struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Diff      string
+	Err       error
+}
+
+// YamlResourceDiffer compares every object of a manifest with the live
+// cluster. The merged object comes from a server-side dry-run apply, so
+// defaults and admission changes are included and nothing is persisted.
+func YamlResourceDiffer(dynamicClient dynamic.Interface, mapper meta.RESTMapper, namespace string, manifests ManifestOptions) ([]DiffResult, error) {
+	objects, err := readManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []DiffResult
+	for _, obj := range objects {
+		results = append(results, diffObject(dynamicClient, mapper, namespace, obj))
+	}
+
+	return results, nil
+}
+
+func diffObject(dynamicClient dynamic.Interface, mapper meta.RESTMapper, namespace string, obj *unstructured.Unstructured) DiffResult {
+	result := DiffResult{Kind: obj.GetKind(), Name: obj.GetName()}
+
+	mapping, err := scopeObject(mapper, namespace, obj)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Namespace = obj.GetNamespace()
+	resource := resourceInterface(dynamicClient, mapping, obj.GetNamespace())
+
+	live, err := resource.Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		live, err = nil, nil
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	// Conflicts are forced so the diff shows the object as applying would
+	// leave it, whichever field manager owns the fields today.
+	merged, err := resource.Apply(context.TODO(), obj.GetName(), obj, metav1.ApplyOptions{
+		FieldManager: FieldManager,
+		Force:        true,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	liveYAML, err := diffableYAML(live)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	mergedYAML, err := diffableYAML(merged)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	path := strings.ToLower(result.Kind) + "/" + result.Name
+	if result.Namespace != "" {
+		path = result.Namespace + "/" + path
+	}
+	result.Diff, result.Err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(liveYAML),
+		B:        difflib.SplitLines(mergedYAML),
+		FromFile: "live/" + path,
+		ToFile:   "merged/" + path,
+		Context:  3,
+	})
+	return result
+}
+
+// diffableYAML renders obj without the fields that only add noise to a
+// diff: managed fields and status. A nil object renders as empty.
+func diffableYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	obj = obj.DeepCopy()
+	obj.SetManagedFields(nil)
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	content, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// addDryRunApplyReactor answers apply requests with the applied object and
+// a status, without storing anything, like a server-side dry run.
+func addDryRunApplyReactor(client *dynamicfake.FakeDynamicClient) {
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		merged := &unstructured.Unstructured{}
+		if err := merged.UnmarshalJSON(action.(k8stesting.PatchAction).GetPatch()); err != nil {
+			return true, nil, err
+		}
+		merged.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply}})
+		unstructured.SetNestedField(merged.Object, "Active", "status", "phase")
+		return true, merged, nil
+	})
+}
+
+func TestYamlResourceDiffer(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: changed
+data:
+  key: new
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: same
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fresh
+data:
+  key: value
+`)
+	changed := newConfigMap("changed", "default")
+	unstructured.SetNestedField(changed.Object, "old", "data", "key")
+	same := newConfigMap("same", "default")
+	unstructured.SetNestedField(same.Object, "value", "data", "key")
+	same.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate}})
+
+	dynamicClient := newFakeDynamicClient(changed, same)
+	addDryRunApplyReactor(dynamicClient)
+
+	results, err := YamlResourceDiffer(dynamicClient, newTestMapper(), "default", manifestPaths(path))
+	if err != nil {
+		t.Fatalf("YamlResourceDiffer returned error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+	diffs := map[string]string{}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatalf("diffing %s failed: %v", result.Name, result.Err)
+		}
+		diffs[result.Name] = result.Diff
+	}
+
+	if diff := diffs["changed"]; !strings.Contains(diff, "-  key: old") || !strings.Contains(diff, "+  key: new") {
+		t.Errorf("expected the data change in the diff, got:\n%s", diff)
+	}
+	if !strings.Contains(diffs["changed"], "--- live/default/configmap/changed") {
+		t.Errorf("expected the live object label, got:\n%s", diffs["changed"])
+	}
+	if diff := diffs["same"]; diff != "" {
+		t.Errorf("expected no diff once managed fields and status are stripped, got:\n%s", diff)
+	}
+	if diff := diffs["fresh"]; !strings.Contains(diff, "+  name: fresh") || strings.Contains(diff, "\n-") {
+		t.Errorf("expected only additions for a new object, got:\n%s", diff)
+	}
+	for name, diff := range diffs {
+		if strings.Contains(diff, "managedFields") || strings.Contains(diff, "status") {
+			t.Errorf("expected managed fields and status to be stripped from %s, got:\n%s", name, diff)
+		}
+	}
+}
+
+func TestYamlResourceDifferReportsFailures(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: denied
+---
+apiVersion: example.com/v1
+kind: Gadget
+metadata:
+  name: unknown
+`)
+	dynamicClient := newFakeDynamicClient()
+	dynamicClient.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(configMapsGVR.GroupResource(), "denied", errors.New("no access"))
+	})
+
+	results, err := YamlResourceDiffer(dynamicClient, newTestMapper(), "default", manifestPaths(path))
+	if err != nil {
+		t.Fatalf("YamlResourceDiffer returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if !apierrors.IsForbidden(results[0].Err) {
+		t.Errorf("expected Forbidden for the config map, got %v", results[0].Err)
+	}
+	if !errors.Is(results[1].Err, ErrUnsupportedKind) {
+		t.Errorf("expected ErrUnsupportedKind for the gadget, got %v", results[1].Err)
+	}
+}
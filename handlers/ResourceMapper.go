@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// resolveKind maps a user supplied kind or resource name such as
+// "Deployment", "deploy", "pvc" or "ingresses.networking.k8s.io" to the
+// API resource served by the cluster.
+func resolveKind(mapper meta.RESTMapper, kind string) (*meta.RESTMapping, error) {
+	groupResource := schema.ParseGroupResource(strings.ToLower(kind))
+
+	gvk, err := mapper.KindFor(groupResource.WithVersion(""))
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKind, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return mappingFor(mapper, gvk)
+}
+
+// mappingFor returns the REST mapping of a fully qualified kind. When the
+// kind is unknown the mapper is reset once, so kinds registered earlier in
+// the same run (for example by a CustomResourceDefinition) can be found.
+func mappingFor(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		if resettable, ok := mapper.(meta.ResettableRESTMapper); ok {
+			resettable.Reset()
+			mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("%w: %s in %s", ErrUnsupportedKind, gvk.Kind, gvk.GroupVersion())
+	}
+	return mapping, err
+}
+
+func isNamespaced(mapping *meta.RESTMapping) bool {
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace
+}
+
+// resourceInterface scopes the dynamic client to namespace for namespaced
+// resources; cluster-scoped resources ignore the namespace.
+func resourceInterface(dynamicClient dynamic.Interface, mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+	if isNamespaced(mapping) {
+		return dynamicClient.Resource(mapping.Resource).Namespace(namespace)
+	}
+	return dynamicClient.Resource(mapping.Resource)
+}
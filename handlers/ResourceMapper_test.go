@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var widgetGVK = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+
+// testKinds are the kinds known to the mapper and fake dynamic client used
+// across the handler tests, including a custom resource.
+var testKinds = []struct {
+	gvk   schema.GroupVersionKind
+	scope meta.RESTScope
+}{
+	{schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot},
+	{schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace},
+	{schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, meta.RESTScopeNamespace},
+	{schema.GroupVersionKind{Version: "v1", Kind: "Service"}, meta.RESTScopeNamespace},
+	{schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace},
+	{schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}, meta.RESTScopeNamespace},
+	{schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace},
+	{schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, meta.RESTScopeNamespace},
+	{schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}, meta.RESTScopeNamespace},
+	{schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot},
+	{widgetGVK, meta.RESTScopeNamespace},
+}
+
+func newTestMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, kind := range testKinds {
+		mapper.Add(kind.gvk, kind.scope)
+	}
+	return mapper
+}
+
+func newFakeDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, kind := range testKinds {
+		plural, _ := meta.UnsafeGuessKindToResource(kind.gvk)
+		listKinds[plural] = kind.gvk.Kind + "List"
+	}
+	// Endpoints are only read by resource, never mapped from a kind.
+	listKinds[endpointsGVR] = "EndpointsList"
+	listKinds[autoscalerGVR] = "HorizontalPodAutoscalerList"
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+}
+
+func TestResolveKind(t *testing.T) {
+	mapper := newTestMapper()
+
+	tests := []struct {
+		kind       string
+		resource   string
+		namespaced bool
+	}{
+		{kind: "Deployment", resource: "deployments", namespaced: true},
+		{kind: "deployments", resource: "deployments", namespaced: true},
+		{kind: "ingress", resource: "ingresses", namespaced: true},
+		{kind: "deployment.apps", resource: "deployments", namespaced: true},
+		{kind: "namespace", resource: "namespaces", namespaced: false},
+		{kind: "clusterrole", resource: "clusterroles", namespaced: false},
+		{kind: "widget", resource: "widgets", namespaced: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			mapping, err := resolveKind(mapper, tt.kind)
+			if err != nil {
+				t.Fatalf("resolveKind(%q) returned error: %v", tt.kind, err)
+			}
+			if mapping.Resource.Resource != tt.resource {
+				t.Errorf("expected resource %q, got %q", tt.resource, mapping.Resource.Resource)
+			}
+			if isNamespaced(mapping) != tt.namespaced {
+				t.Errorf("expected namespaced=%v", tt.namespaced)
+			}
+		})
+	}
+}
+
+func TestResolveKindUnsupported(t *testing.T) {
+	if _, err := resolveKind(newTestMapper(), "gadget"); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected ErrUnsupportedKind for a kind the cluster does not serve, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// RollbackCreated deletes the objects that were created successfully, in
+// the reverse order of their creation, so workloads go before the
+// configuration and namespaces they depend on. Every object is attempted
+// and its outcome reported, even when an earlier deletion fails.
+func RollbackCreated(dynamicClient dynamic.Interface, mapper meta.RESTMapper, results []CreateResult) []DeleteResult {
+	var rollback []DeleteResult
+	for i := len(results) - 1; i >= 0; i-- {
+		created := results[i]
+		if created.Err != nil {
+			continue
+		}
+
+		result := DeleteResult{Kind: created.Kind, Name: created.Name, Namespace: created.Namespace}
+		mapping, err := mappingFor(mapper, created.Object.GroupVersionKind())
+		if err != nil {
+			result.Err = err
+		} else {
+			propagation := metav1.DeletePropagationBackground
+			result.Err = resourceInterface(dynamicClient, mapping, created.Namespace).Delete(context.TODO(), created.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		}
+		rollback = append(rollback, result)
+	}
+	return rollback
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestYamlResourceCreatorAtomicStopsAtFirstFailure(t *testing.T) {
+	path := writeManifest(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: existing
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`)
+	dynamicClient := newFakeDynamicClient(newObject("v1", "Service", "existing", "default", nil))
+
+	results, err := YamlResourceCreatorAtomic(dynamicClient, newTestMapper(), "default", manifestPaths(path), DryRunNone)
+	if err != nil {
+		t.Fatalf("YamlResourceCreatorAtomic returned error: %v", err)
+	}
+	if len(results) != 2 || results[0].Err != nil || !apierrors.IsAlreadyExists(results[1].Err) {
+		t.Fatalf("expected the config map and the failed service, got %+v", results)
+	}
+	if _, err := dynamicClient.Resource(deploymentsGVR).Namespace("default").Get(context.TODO(), "web", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the deployment after the failure not to be created, got %v", err)
+	}
+}
+
+func TestRollbackCreated(t *testing.T) {
+	namespace := newObject("v1", "Namespace", "team-a", "", nil)
+	settings := newConfigMap("settings", "team-a")
+	deployment := newObject("apps/v1", "Deployment", "web", "team-a", nil)
+	dynamicClient := newFakeDynamicClient(namespace, settings, deployment)
+
+	results := []CreateResult{
+		{Kind: "Namespace", Name: "team-a", Object: namespace},
+		{Kind: "ConfigMap", Name: "settings", Namespace: "team-a", Object: settings},
+		{Kind: "Deployment", Name: "web", Namespace: "team-a", Object: deployment},
+		{Kind: "Service", Name: "web", Namespace: "team-a", Object: newObject("v1", "Service", "web", "team-a", nil), Err: errors.New("create failed")},
+	}
+
+	rollback := RollbackCreated(dynamicClient, newTestMapper(), results)
+
+	var order []string
+	for _, result := range rollback {
+		if result.Err != nil {
+			t.Errorf("rolling back %s %s: %v", result.Kind, result.Name, result.Err)
+		}
+		order = append(order, result.Kind)
+	}
+	if len(order) != 3 || order[0] != "Deployment" || order[1] != "ConfigMap" || order[2] != "Namespace" {
+		t.Errorf("expected the created objects to be deleted in reverse order, got %v", order)
+	}
+
+	var deleted []string
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "delete" {
+			deleted = append(deleted, action.(k8stesting.DeleteAction).GetName())
+		}
+	}
+	if len(deleted) != 3 || deleted[0] != "web" || deleted[2] != "team-a" {
+		t.Errorf("expected delete requests in reverse order, got %v", deleted)
+	}
+}
+
+func TestRollbackCreatedReportsFailures(t *testing.T) {
+	settings := newConfigMap("settings", "default")
+	gone := newConfigMap("gone", "default")
+	dynamicClient := newFakeDynamicClient(settings)
+
+	rollback := RollbackCreated(dynamicClient, newTestMapper(), []CreateResult{
+		{Kind: "ConfigMap", Name: "settings", Namespace: "default", Object: settings},
+		{Kind: "ConfigMap", Name: "gone", Namespace: "default", Object: gone},
+	})
+
+	if len(rollback) != 2 || !apierrors.IsNotFound(rollback[0].Err) || rollback[1].Err != nil {
+		t.Errorf("expected a NotFound for gone and settings to be deleted, got %+v", rollback)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var autoscalerGVR = schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}
+
+// ScaleResult is the outcome of scaling an object.
+type ScaleResult struct {
+	Kind             string
+	Name             string
+	Namespace        string
+	PreviousReplicas int64
+	Replicas         int64
+	// Autoscalers are the HorizontalPodAutoscalers targeting the object,
+	// which will undo a manual scale.
+	Autoscalers []string
+}
+
+// ScaleResource sets the replicas of any object served with a scale
+// subresource. With currentReplicas of 0 or more, the object must have that
+// many replicas or a Conflict error is returned; the update itself is
+// conditional on the scale being unchanged since it was read. Objects
+// targeted by a HorizontalPodAutoscaler are refused with ErrAutoscaled
+// unless force is set.
+func ScaleResource(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, kind string, name string, namespace string, replicas int64, currentReplicas int64, force bool) (ScaleResult, error) {
+	mapping, err := resolveKind(mapper, kind)
+	if err != nil {
+		return ScaleResult{}, err
+	}
+	namespace = scopedNamespace(mapping, namespace)
+	result := ScaleResult{Kind: mapping.GroupVersionKind.Kind, Name: name, Namespace: namespace, Replicas: replicas}
+
+	if isNamespaced(mapping) {
+		result.Autoscalers, err = autoscalersFor(ctx, dynamicClient, mapping.GroupVersionKind.GroupKind(), name, namespace)
+		if err != nil {
+			return result, fmt.Errorf("listing autoscalers: %w", err)
+		}
+	}
+	if len(result.Autoscalers) > 0 && !force {
+		return result, fmt.Errorf("%w: %s %s is scaled by HorizontalPodAutoscaler %s", ErrAutoscaled, result.Kind, name, strings.Join(result.Autoscalers, ", "))
+	}
+
+	client := resourceInterface(dynamicClient, mapping, namespace)
+	scale, err := client.Get(ctx, name, metav1.GetOptions{}, "scale")
+	if err != nil {
+		return result, err
+	}
+	result.PreviousReplicas, _, _ = unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	if currentReplicas >= 0 && result.PreviousReplicas != currentReplicas {
+		return result, apierrors.NewConflict(mapping.Resource.GroupResource(), name,
+			fmt.Errorf("expected %d current replicas, found %d", currentReplicas, result.PreviousReplicas))
+	}
+
+	if err := unstructured.SetNestedField(scale.Object, replicas, "spec", "replicas"); err != nil {
+		return result, err
+	}
+	// The scale keeps the resourceVersion it was read with, so a change
+	// made in between fails with a Conflict instead of being overwritten.
+	_, err = client.Update(ctx, scale, metav1.UpdateOptions{}, "scale")
+	return result, err
+}
+
+// WaitForScaled watches an object until status.readyReplicas equals
+// replicas and its controller has observed the latest spec. The wait ends
+// with ErrWaitTimeout when ctx expires.
+func WaitForScaled(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, kind string, name string, namespace string, replicas int64) error {
+	mapping, err := resolveKind(mapper, kind)
+	if err != nil {
+		return err
+	}
+	return watchUntil(ctx, dynamicClient, mapping.Resource, scopedNamespace(mapping, namespace), name, func(obj *unstructured.Unstructured) (bool, error) {
+		observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+		if found && observed < obj.GetGeneration() {
+			return false, nil
+		}
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		return ready == replicas, nil
+	})
+}
+
+// autoscalersFor returns the names of the HorizontalPodAutoscalers in
+// namespace whose scale target is the named object. Clusters that do not
+// serve autoscaling/v2 have none.
+func autoscalersFor(ctx context.Context, dynamicClient dynamic.Interface, target schema.GroupKind, name string, namespace string) ([]string, error) {
+	list, err := dynamicClient.Resource(autoscalerGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, autoscaler := range list.Items {
+		ref, _, _ := unstructured.NestedStringMap(autoscaler.Object, "spec", "scaleTargetRef")
+		groupVersion, err := schema.ParseGroupVersion(ref["apiVersion"])
+		if err != nil {
+			continue
+		}
+		if ref["kind"] == target.Kind && groupVersion.Group == target.Group && ref["name"] == name {
+			names = append(names, autoscaler.GetName())
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// withScaleSubresource serves the scale subresource from the objects of
+// the fake client, which otherwise treats it as the object itself.
+func withScaleSubresource(dynamicClient *dynamicfake.FakeDynamicClient) *dynamicfake.FakeDynamicClient {
+	tracker := dynamicClient.Tracker()
+	dynamicClient.PrependReactor("get", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		get := action.(clienttesting.GetAction)
+		if get.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		obj, err := tracker.Get(get.GetResource(), get.GetNamespace(), get.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		object := obj.(*unstructured.Unstructured)
+		replicas, _, _ := unstructured.NestedInt64(object.Object, "spec", "replicas")
+		scale := newObject("autoscaling/v1", "Scale", object.GetName(), object.GetNamespace(), nil)
+		scale.SetResourceVersion(object.GetResourceVersion())
+		unstructured.SetNestedField(scale.Object, replicas, "spec", "replicas")
+		return true, scale, nil
+	})
+	dynamicClient.PrependReactor("update", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		update := action.(clienttesting.UpdateAction)
+		if update.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		scale := update.GetObject().(*unstructured.Unstructured)
+		obj, err := tracker.Get(update.GetResource(), update.GetNamespace(), scale.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		object := obj.(*unstructured.Unstructured)
+		replicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+		unstructured.SetNestedField(object.Object, replicas, "spec", "replicas")
+		return true, scale, tracker.Update(update.GetResource(), object, update.GetNamespace())
+	})
+	return dynamicClient
+}
+
+func newAutoscaler(name string, targetKind string, targetName string) *unstructured.Unstructured {
+	autoscaler := newObject("autoscaling/v2", "HorizontalPodAutoscaler", name, "default", nil)
+	unstructured.SetNestedStringMap(autoscaler.Object, map[string]string{
+		"apiVersion": "apps/v1", "kind": targetKind, "name": targetName,
+	}, "spec", "scaleTargetRef")
+	return autoscaler
+}
+
+func replicasOf(t *testing.T, dynamicClient *dynamicfake.FakeDynamicClient, name string) int64 {
+	t.Helper()
+	deployment, err := dynamicClient.Resource(deploymentsGVR).Namespace("default").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting deployment %s: %v", name, err)
+	}
+	replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	return replicas
+}
+
+func TestScaleResource(t *testing.T) {
+	dynamicClient := withScaleSubresource(newFakeDynamicClient(newDeployment("web", 2, 2), newDeployment("api", 2, 2)))
+	mapper := newTestMapper()
+
+	result, err := ScaleResource(context.TODO(), dynamicClient, mapper, "deployments", "web", "default", 5, -1, false)
+	if err != nil {
+		t.Fatalf("ScaleResource returned error: %v", err)
+	}
+	if result.Kind != "Deployment" || result.PreviousReplicas != 2 || result.Replicas != 5 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if replicas := replicasOf(t, dynamicClient, "web"); replicas != 5 {
+		t.Errorf("expected 5 replicas, got %d", replicas)
+	}
+
+	if _, err := ScaleResource(context.TODO(), dynamicClient, mapper, "deployments", "api", "default", 3, 4, false); !apierrors.IsConflict(err) {
+		t.Errorf("expected a Conflict for a failed precondition, got %v", err)
+	}
+	if _, err := ScaleResource(context.TODO(), dynamicClient, mapper, "deployments", "api", "default", 3, 2, false); err != nil {
+		t.Errorf("expected the precondition to hold, got %v", err)
+	}
+	if _, err := ScaleResource(context.TODO(), dynamicClient, mapper, "deployments", "missing", "default", 3, -1, false); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+func TestScaleResourceWithAutoscaler(t *testing.T) {
+	dynamicClient := withScaleSubresource(newFakeDynamicClient(
+		newDeployment("web", 2, 2),
+		newAutoscaler("web-hpa", "Deployment", "web"),
+		newAutoscaler("other-hpa", "Deployment", "other"),
+	))
+	mapper := newTestMapper()
+
+	result, err := ScaleResource(context.TODO(), dynamicClient, mapper, "deployment", "web", "default", 5, -1, false)
+	if !errors.Is(err, ErrAutoscaled) {
+		t.Fatalf("expected ErrAutoscaled, got %v", err)
+	}
+	if len(result.Autoscalers) != 1 || result.Autoscalers[0] != "web-hpa" {
+		t.Errorf("expected web-hpa, got %v", result.Autoscalers)
+	}
+	if replicas := replicasOf(t, dynamicClient, "web"); replicas != 2 {
+		t.Errorf("expected the refused scale to leave 2 replicas, got %d", replicas)
+	}
+
+	if _, err := ScaleResource(context.TODO(), dynamicClient, mapper, "deployment", "web", "default", 5, -1, true); err != nil {
+		t.Fatalf("expected force to scale anyway, got %v", err)
+	}
+	if replicas := replicasOf(t, dynamicClient, "web"); replicas != 5 {
+		t.Errorf("expected 5 replicas, got %d", replicas)
+	}
+}
+
+func TestWaitForScaled(t *testing.T) {
+	deployment := newDeployment("web", 3, 1)
+	unstructured.SetNestedField(deployment.Object, int64(1), "status", "readyReplicas")
+	dynamicClient := newFakeDynamicClient(deployment)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- WaitForScaled(ctx, dynamicClient, newTestMapper(), "deployment", "web", "default", 3)
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("WaitForScaled returned before the replicas were ready: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unstructured.SetNestedField(deployment.Object, int64(3), "status", "readyReplicas")
+	if _, err := dynamicClient.Resource(deploymentsGVR).Namespace("default").Update(context.TODO(), deployment, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("updating deployment: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("WaitForScaled returned error: %v", err)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+	if err := WaitForScaled(short, dynamicClient, newTestMapper(), "deployment", "web", "default", 5); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("expected ErrWaitTimeout, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// WatchDeployments calls update with the deployments of namespace, all
+// namespaces when empty, once they are listed and again after every add,
+// update or delete seen by a shared informer. Events that arrive while
+// update runs are coalesced into one call. It returns when ctx is done.
+func WatchDeployments(ctx context.Context, clientset kubernetes.Interface, namespace string, update func([]DeploymentInfo)) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace(namespace))
+	deployments := factory.Apps().V1().Deployments()
+
+	return watchInformers(ctx, factory, func() error {
+		list, err := deployments.Lister().List(labels.Everything())
+		if err != nil {
+			return err
+		}
+		sortObjects(list, func(d *appsv1.Deployment) (string, string) { return d.Namespace, d.Name })
+
+		var deploymentList []DeploymentInfo
+		for _, deployment := range list {
+			deploymentList = append(deploymentList, deploymentInfoOf(*deployment))
+		}
+		update(deploymentList)
+		return nil
+	}, deployments.Informer())
+}
+
+// WatchResourceInfos is WatchDeployments for the deployments and services
+// listed by ResourceInfos.
+func WatchResourceInfos(ctx context.Context, clientset kubernetes.Interface, namespace string, update func([]ResourceInfo)) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace(namespace))
+	deployments := factory.Apps().V1().Deployments()
+	services := factory.Core().V1().Services()
+
+	return watchInformers(ctx, factory, func() error {
+		deploymentList, err := deployments.Lister().List(labels.Everything())
+		if err != nil {
+			return err
+		}
+		serviceList, err := services.Lister().List(labels.Everything())
+		if err != nil {
+			return err
+		}
+		sortObjects(deploymentList, func(d *appsv1.Deployment) (string, string) { return d.Namespace, d.Name })
+		sortObjects(serviceList, func(s *corev1.Service) (string, string) { return s.Namespace, s.Name })
+
+		var resources []ResourceInfo
+		for _, deployment := range deploymentList {
+			resources = append(resources, resourceInfoOf("Deployment", deployment.ObjectMeta))
+		}
+		for _, service := range serviceList {
+			resources = append(resources, resourceInfoOf("Service", service.ObjectMeta))
+		}
+		update(resources)
+		return nil
+	}, deployments.Informer(), services.Informer())
+}
+
+// watchInformers starts the informers, renders once their caches are
+// synced and renders again whenever one of them sees a change.
+func watchInformers(ctx context.Context, factory informers.SharedInformerFactory, render func() error, informerList ...cache.SharedIndexInformer) error {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	}
+	for _, informer := range informerList {
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return err
+		}
+	}
+
+	factory.Start(ctx.Done())
+	defer factory.Shutdown()
+	for informerType, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced && ctx.Err() == nil {
+			return fmt.Errorf("syncing %v cache failed", informerType)
+		}
+	}
+	// The adds of the initial list are part of the first render.
+	select {
+	case <-changed:
+	default:
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := render(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+func sortObjects[T any](objects []T, key func(T) (string, string)) {
+	sort.Slice(objects, func(i, j int) bool {
+		namespaceI, nameI := key(objects[i])
+		namespaceJ, nameJ := key(objects[j])
+		if namespaceI != namespaceJ {
+			return namespaceI < namespaceJ
+		}
+		return nameI < nameJ
+	})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWatchDeployments(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updates := make(chan []DeploymentInfo, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- WatchDeployments(ctx, clientset, "default", func(list []DeploymentInfo) { updates <- list })
+	}()
+
+	if list := <-updates; len(list) != 1 || list[0].Name != "web" {
+		t.Fatalf("expected the listed deployment first, got %+v", list)
+	}
+
+	worker := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+	}
+	if _, err := clientset.AppsV1().Deployments("default").Create(context.TODO(), worker, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("creating deployment: %v", err)
+	}
+	for list := range updates {
+		if len(list) == 2 {
+			if list[0].Name != "web" || list[1].Name != "worker" {
+				t.Errorf("expected deployments sorted by name, got %+v", list)
+			}
+			break
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("WatchDeployments returned error: %v", err)
+	}
+}
+
+func TestWatchResourceInfosDelete(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}, Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(1)}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	updates := make(chan []ResourceInfo, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- WatchResourceInfos(ctx, clientset, "", func(resources []ResourceInfo) { updates <- resources })
+	}()
+
+	if resources := <-updates; len(resources) != 2 || resources[0].Kind != "Deployment" {
+		t.Fatalf("expected the deployment and the service, got %+v", resources)
+	}
+	if err := clientset.CoreV1().Services("default").Delete(context.TODO(), "web", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("deleting service: %v", err)
+	}
+	for resources := range updates {
+		if len(resources) == 1 {
+			break
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("WatchResourceInfos returned error: %v", err)
+	}
+}
@@ -0,0 +1,518 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// Kinds that support rollouts.
+const (
+	RolloutDeployment  = "Deployment"
+	RolloutStatefulSet = "StatefulSet"
+	RolloutDaemonSet   = "DaemonSet"
+)
+
+// Annotations read and written by rollouts.
+const (
+	ChangeCauseAnnotation = "kubernetes.io/change-cause"
+	RestartedAtAnnotation = "kuba.io/restartedAt"
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+)
+
+// RolloutRevision is one revision in the history of a workload.
+type RolloutRevision struct {
+	Revision    int64  `json:"revision"`
+	ChangeCause string `json:"changeCause"`
+	Images      string `json:"images"`
+}
+
+// ParseRolloutKind returns the rollout kind named by kind, which may be
+// plural or a short name such as "deploy", "sts" or "ds".
+func ParseRolloutKind(kind string) (string, error) {
+	switch strings.ToLower(kind) {
+	case "deployment", "deployments", "deploy":
+		return RolloutDeployment, nil
+	case "statefulset", "statefulsets", "sts":
+		return RolloutStatefulSet, nil
+	case "daemonset", "daemonsets", "ds":
+		return RolloutDaemonSet, nil
+	}
+	return "", fmt.Errorf("%w: rollouts are supported for deployments, statefulsets and daemonsets, got %q", ErrUnsupportedKind, kind)
+}
+
+// RolloutStatus watches a workload until its rollout is complete, passing
+// every new progress message to report. A deployment that exceeds its
+// progress deadline fails with ErrNotReady; the wait ends with
+// ErrWaitTimeout when ctx expires.
+func RolloutStatus(ctx context.Context, clientset kubernetes.Interface, kind string, name string, namespace string, report func(string)) error {
+	listWatch, example, err := rolloutListWatch(ctx, clientset, kind, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	lastMessage := ""
+	_, err = watchtools.UntilWithSync(ctx, listWatch, example, nil, func(event watch.Event) (bool, error) {
+		if event.Type == watch.Deleted {
+			return false, fmt.Errorf("%s %s was deleted", strings.ToLower(kind), name)
+		}
+		message, done, err := rolloutStatusOf(event.Object)
+		if err != nil {
+			return false, err
+		}
+		if message != lastMessage {
+			report(message)
+			lastMessage = message
+		}
+		return done, nil
+	})
+	if wait.Interrupted(err) {
+		return fmt.Errorf("%w for the rollout of %s %s", ErrWaitTimeout, strings.ToLower(kind), name)
+	}
+	return err
+}
+
+func rolloutListWatch(ctx context.Context, clientset kubernetes.Interface, kind string, name string, namespace string) (*cache.ListWatch, runtime.Object, error) {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	var list func(v1.ListOptions) (runtime.Object, error)
+	var watchFunc func(v1.ListOptions) (watch.Interface, error)
+	var example runtime.Object
+
+	apps := clientset.AppsV1()
+	switch kind {
+	case RolloutDeployment:
+		list = func(options v1.ListOptions) (runtime.Object, error) {
+			return apps.Deployments(namespace).List(ctx, options)
+		}
+		watchFunc = func(options v1.ListOptions) (watch.Interface, error) {
+			return apps.Deployments(namespace).Watch(ctx, options)
+		}
+		example = &appsv1.Deployment{}
+	case RolloutStatefulSet:
+		list = func(options v1.ListOptions) (runtime.Object, error) {
+			return apps.StatefulSets(namespace).List(ctx, options)
+		}
+		watchFunc = func(options v1.ListOptions) (watch.Interface, error) {
+			return apps.StatefulSets(namespace).Watch(ctx, options)
+		}
+		example = &appsv1.StatefulSet{}
+	case RolloutDaemonSet:
+		list = func(options v1.ListOptions) (runtime.Object, error) {
+			return apps.DaemonSets(namespace).List(ctx, options)
+		}
+		watchFunc = func(options v1.ListOptions) (watch.Interface, error) {
+			return apps.DaemonSets(namespace).Watch(ctx, options)
+		}
+		example = &appsv1.DaemonSet{}
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedKind, kind)
+	}
+
+	return &cache.ListWatch{
+		ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return list(options)
+		},
+		WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return watchFunc(options)
+		},
+	}, example, nil
+}
+
+// rolloutStatusOf describes the progress of a rollout, as kubectl does,
+// and reports whether it is complete.
+func rolloutStatusOf(obj runtime.Object) (string, bool, error) {
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		return deploymentRolloutStatus(workload)
+	case *appsv1.StatefulSet:
+		return statefulSetRolloutStatus(workload)
+	case *appsv1.DaemonSet:
+		return daemonSetRolloutStatus(workload)
+	}
+	return "", false, fmt.Errorf("%w: %T", ErrUnsupportedKind, obj)
+}
+
+func deploymentRolloutStatus(deployment *appsv1.Deployment) (string, bool, error) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return "Waiting for deployment spec update to be observed...", false, nil
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return "", false, fmt.Errorf("%w: deployment %q exceeded its progress deadline", ErrNotReady, deployment.Name)
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	switch {
+	case status.UpdatedReplicas < replicas:
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...", deployment.Name, status.UpdatedReplicas, replicas), false, nil
+	case status.Replicas > status.UpdatedReplicas:
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...", deployment.Name, status.Replicas-status.UpdatedReplicas), false, nil
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...", deployment.Name, status.AvailableReplicas, status.UpdatedReplicas), false, nil
+	}
+	return fmt.Sprintf("deployment %q successfully rolled out", deployment.Name), true, nil
+}
+
+func statefulSetRolloutStatus(statefulSet *appsv1.StatefulSet) (string, bool, error) {
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return "", false, fmt.Errorf("rollout status is only available for the %s strategy", appsv1.RollingUpdateStatefulSetStrategyType)
+	}
+	if statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		return "Waiting for statefulset spec update to be observed...", false, nil
+	}
+
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	status := statefulSet.Status
+	if status.ReadyReplicas < replicas {
+		return fmt.Sprintf("Waiting for %d pods to be ready...", replicas-status.ReadyReplicas), false, nil
+	}
+	if rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		partitioned := replicas - *rollingUpdate.Partition
+		if status.UpdatedReplicas < partitioned {
+			return fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated...", status.UpdatedReplicas, partitioned), false, nil
+		}
+		return fmt.Sprintf("partitioned roll out complete: %d new pods have been updated...", status.UpdatedReplicas), true, nil
+	}
+	if status.UpdateRevision != status.CurrentRevision {
+		return fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s...", status.UpdatedReplicas, status.UpdateRevision), false, nil
+	}
+	return fmt.Sprintf("statefulset rolling update complete %d pods at revision %s...", status.CurrentReplicas, status.CurrentRevision), true, nil
+}
+
+func daemonSetRolloutStatus(daemonSet *appsv1.DaemonSet) (string, bool, error) {
+	if daemonSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return "", false, fmt.Errorf("rollout status is only available for the %s strategy", appsv1.RollingUpdateDaemonSetStrategyType)
+	}
+	if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		return "Waiting for daemon set spec update to be observed...", false, nil
+	}
+
+	status := daemonSet.Status
+	if status.UpdatedNumberScheduled < status.DesiredNumberScheduled {
+		return fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated...", daemonSet.Name, status.UpdatedNumberScheduled, status.DesiredNumberScheduled), false, nil
+	}
+	if status.NumberAvailable < status.DesiredNumberScheduled {
+		return fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d of %d updated pods are available...", daemonSet.Name, status.NumberAvailable, status.DesiredNumberScheduled), false, nil
+	}
+	return fmt.Sprintf("daemon set %q successfully rolled out", daemonSet.Name), true, nil
+}
+
+// RolloutHistory lists the revisions of a workload, oldest first. Revisions
+// of deployments come from their ReplicaSets, the others from their
+// ControllerRevisions.
+func RolloutHistory(clientset kubernetes.Interface, kind string, name string, namespace string) ([]RolloutRevision, error) {
+	if kind == RolloutDeployment {
+		replicaSets, err := deploymentReplicaSets(clientset, name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		var history []RolloutRevision
+		for _, replicaSet := range replicaSets {
+			history = append(history, RolloutRevision{
+				Revision:    replicaSetRevision(&replicaSet),
+				ChangeCause: replicaSet.Annotations[ChangeCauseAnnotation],
+				Images:      templateImages(replicaSet.Spec.Template.Spec.Containers),
+			})
+		}
+		return history, nil
+	}
+
+	revisions, err := controllerRevisions(clientset, kind, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var history []RolloutRevision
+	for _, revision := range revisions {
+		history = append(history, RolloutRevision{
+			Revision:    revision.Revision,
+			ChangeCause: revision.Annotations[ChangeCauseAnnotation],
+			Images:      revisionImages(revision.Data.Raw),
+		})
+	}
+	return history, nil
+}
+
+// RolloutUndo rolls a workload back to toRevision, the previous revision
+// when 0, and returns the revision it rolled back to.
+func RolloutUndo(clientset kubernetes.Interface, kind string, name string, namespace string, toRevision int64) (int64, error) {
+	if kind == RolloutDeployment {
+		return undoDeployment(clientset, name, namespace, toRevision)
+	}
+
+	revisions, err := controllerRevisions(clientset, kind, name, namespace)
+	if err != nil {
+		return 0, err
+	}
+	var revisionNumbers []int64
+	for _, revision := range revisions {
+		revisionNumbers = append(revisionNumbers, revision.Revision)
+	}
+	index, err := undoTarget(revisionNumbers, toRevision)
+	if err != nil {
+		return 0, err
+	}
+
+	// ControllerRevisions store the template as a strategic merge patch.
+	patch := revisions[index].Data.Raw
+	apps := clientset.AppsV1()
+	if kind == RolloutStatefulSet {
+		_, err = apps.StatefulSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, v1.PatchOptions{FieldManager: FieldManager})
+	} else {
+		_, err = apps.DaemonSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, v1.PatchOptions{FieldManager: FieldManager})
+	}
+	return revisions[index].Revision, err
+}
+
+func undoDeployment(clientset kubernetes.Interface, name string, namespace string, toRevision int64) (int64, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), name, v1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	if deployment.Spec.Paused {
+		return 0, fmt.Errorf("deployment %s is paused, resume it before rolling back", name)
+	}
+	replicaSets, err := deploymentReplicaSets(clientset, name, namespace)
+	if err != nil {
+		return 0, err
+	}
+	var revisionNumbers []int64
+	for i := range replicaSets {
+		revisionNumbers = append(revisionNumbers, replicaSetRevision(&replicaSets[i]))
+	}
+	index, err := undoTarget(revisionNumbers, toRevision)
+	if err != nil {
+		return 0, err
+	}
+
+	replicaSet := replicaSets[index]
+	template := *replicaSet.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	deployment.Spec.Template = template
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	if cause, found := replicaSet.Annotations[ChangeCauseAnnotation]; found {
+		deployment.Annotations[ChangeCauseAnnotation] = cause
+	} else {
+		delete(deployment.Annotations, ChangeCauseAnnotation)
+	}
+	_, err = clientset.AppsV1().Deployments(namespace).Update(context.TODO(), deployment, v1.UpdateOptions{FieldManager: FieldManager})
+	return revisionNumbers[index], err
+}
+
+// undoTarget returns the index of toRevision in revisions, sorted oldest
+// first, or of the revision before the current one when toRevision is 0.
+func undoTarget(revisions []int64, toRevision int64) (int, error) {
+	if toRevision == 0 {
+		if len(revisions) < 2 {
+			return 0, fmt.Errorf("no previous revision to roll back to")
+		}
+		return len(revisions) - 2, nil
+	}
+	for i, revision := range revisions {
+		if revision == toRevision {
+			return i, nil
+		}
+	}
+	return 0, apierrors.NewNotFound(appsv1.Resource("revision"), strconv.FormatInt(toRevision, 10))
+}
+
+// RolloutRestart restarts the pods of a workload by stamping its pod
+// template with the RestartedAtAnnotation, which starts a new rollout.
+func RolloutRestart(clientset kubernetes.Interface, kind string, name string, namespace string, now time.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{RestartedAtAnnotation: now.Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if kind == RolloutDeployment {
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), name, v1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if deployment.Spec.Paused {
+			return fmt.Errorf("deployment %s is paused, resume it before restarting", name)
+		}
+	}
+	return patchWorkload(clientset, kind, name, namespace, patch)
+}
+
+// patchWorkload applies a strategic merge patch to a workload.
+func patchWorkload(clientset kubernetes.Interface, kind string, name string, namespace string, patch []byte) error {
+	apps := clientset.AppsV1()
+	options := v1.PatchOptions{FieldManager: FieldManager}
+	var err error
+	switch kind {
+	case RolloutDeployment:
+		_, err = apps.Deployments(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, options)
+	case RolloutStatefulSet:
+		_, err = apps.StatefulSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, options)
+	case RolloutDaemonSet:
+		_, err = apps.DaemonSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, options)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedKind, kind)
+	}
+	return err
+}
+
+// workloadTemplate returns the pod template of a workload.
+func workloadTemplate(clientset kubernetes.Interface, kind string, name string, namespace string) (*corev1.PodTemplateSpec, error) {
+	apps := clientset.AppsV1()
+	switch kind {
+	case RolloutDeployment:
+		deployment, err := apps.Deployments(namespace).Get(context.TODO(), name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &deployment.Spec.Template, nil
+	case RolloutStatefulSet:
+		statefulSet, err := apps.StatefulSets(namespace).Get(context.TODO(), name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &statefulSet.Spec.Template, nil
+	case RolloutDaemonSet:
+		daemonSet, err := apps.DaemonSets(namespace).Get(context.TODO(), name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &daemonSet.Spec.Template, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedKind, kind)
+}
+
+// deploymentReplicaSets returns the ReplicaSets owned by a deployment,
+// oldest revision first.
+func deploymentReplicaSets(clientset kubernetes.Interface, name string, namespace string) ([]appsv1.ReplicaSet, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), name, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	selector, err := v1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), v1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	var replicaSets []appsv1.ReplicaSet
+	for _, replicaSet := range list.Items {
+		if ownedBy(replicaSet.OwnerReferences, deployment.UID) {
+			replicaSets = append(replicaSets, replicaSet)
+		}
+	}
+	sort.Slice(replicaSets, func(i, j int) bool {
+		return replicaSetRevision(&replicaSets[i]) < replicaSetRevision(&replicaSets[j])
+	})
+	return replicaSets, nil
+}
+
+// controllerRevisions returns the ControllerRevisions owned by a
+// statefulset or daemonset, oldest first.
+func controllerRevisions(clientset kubernetes.Interface, kind string, name string, namespace string) ([]appsv1.ControllerRevision, error) {
+	var owner v1.Object
+	var labelSelector *v1.LabelSelector
+	switch kind {
+	case RolloutStatefulSet:
+		statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner, labelSelector = statefulSet, statefulSet.Spec.Selector
+	case RolloutDaemonSet:
+		daemonSet, err := clientset.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, v1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		owner, labelSelector = daemonSet, daemonSet.Spec.Selector
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKind, kind)
+	}
+
+	selector, err := v1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := clientset.AppsV1().ControllerRevisions(namespace).List(context.TODO(), v1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []appsv1.ControllerRevision
+	for _, revision := range list.Items {
+		if ownedBy(revision.OwnerReferences, owner.GetUID()) {
+			revisions = append(revisions, revision)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+func ownedBy(references []v1.OwnerReference, uid types.UID) bool {
+	for _, reference := range references {
+		if reference.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+func replicaSetRevision(replicaSet *appsv1.ReplicaSet) int64 {
+	revision, _ := strconv.ParseInt(replicaSet.Annotations[revisionAnnotation], 10, 64)
+	return revision
+}
+
+// revisionImages reads the images from the template stored in a
+// ControllerRevision.
+func revisionImages(data []byte) string {
+	var revision appsv1.StatefulSet
+	if err := json.Unmarshal(data, &revision); err != nil {
+		return ""
+	}
+	return templateImages(revision.Spec.Template.Spec.Containers)
+}
+
+func templateImages(containers []corev1.Container) string {
+	var images []string
+	for _, container := range containers {
+		images = append(images, container.Image)
+	}
+	return strings.Join(images, ",")
+}
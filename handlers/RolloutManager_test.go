@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var webLabels = map[string]string{"app": "web"}
+
+func newRolloutDeployment(image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: types.UID("web-uid"), Generation: 1},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(2),
+			Selector: &metav1.LabelSelector{MatchLabels: webLabels},
+			Template: podTemplate(image),
+		},
+		Status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+}
+
+func podTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: webLabels},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+	}
+}
+
+func newReplicaSet(revision int, image string, cause string) *appsv1.ReplicaSet {
+	template := podTemplate(image)
+	template.Labels = map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: "hash" + strconv.Itoa(revision)}
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-" + strconv.Itoa(revision),
+			Namespace:       "default",
+			Labels:          webLabels,
+			Annotations:     map[string]string{revisionAnnotation: strconv.Itoa(revision)},
+			OwnerReferences: []metav1.OwnerReference{{UID: types.UID("web-uid")}},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: template},
+	}
+	if cause != "" {
+		replicaSet.Annotations[ChangeCauseAnnotation] = cause
+	}
+	return replicaSet
+}
+
+func TestParseRolloutKind(t *testing.T) {
+	if kind, err := ParseRolloutKind("sts"); err != nil || kind != RolloutStatefulSet {
+		t.Errorf("expected StatefulSet, got %q, %v", kind, err)
+	}
+	if _, err := ParseRolloutKind("job"); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected ErrUnsupportedKind, got %v", err)
+	}
+}
+
+func TestRolloutStatusStatuses(t *testing.T) {
+	rolling := newRolloutDeployment("web:2")
+	rolling.Status.UpdatedReplicas = 1
+	unobserved := newRolloutDeployment("web:2")
+	unobserved.Generation = 2
+	partitioned := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: int32Ptr(3),
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32Ptr(2)},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1},
+	}
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent"},
+		Spec:       appsv1.DaemonSetSpec{UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType}},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2},
+	}
+
+	tests := []struct {
+		name string
+		obj  runtime.Object
+		done bool
+	}{
+		{"rolled out deployment", newRolloutDeployment("web:2"), true},
+		{"rolling deployment", rolling, false},
+		{"unobserved deployment", unobserved, false},
+		{"partitioned statefulset", partitioned, true},
+		{"unavailable daemonset", daemonSet, false},
+	}
+	for _, tt := range tests {
+		message, done, err := rolloutStatusOf(tt.obj)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if done != tt.done || message == "" {
+			t.Errorf("%s: expected done %v, got %v with %q", tt.name, tt.done, done, message)
+		}
+	}
+
+	failed := newRolloutDeployment("web:2")
+	failed.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"}}
+	if _, _, err := rolloutStatusOf(failed); !errors.Is(err, ErrNotReady) {
+		t.Errorf("expected ErrNotReady for an exceeded deadline, got %v", err)
+	}
+}
+
+func TestRolloutStatusWatchesUntilComplete(t *testing.T) {
+	rolling := newRolloutDeployment("web:2")
+	rolling.Status.UpdatedReplicas = 1
+	clientset := fake.NewSimpleClientset(rolling)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	messages := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- RolloutStatus(ctx, clientset, RolloutDeployment, "web", "default", func(message string) { messages <- message })
+	}()
+
+	<-messages
+	if _, err := clientset.AppsV1().Deployments("default").UpdateStatus(context.TODO(), newRolloutDeployment("web:2"), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("updating deployment: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("RolloutStatus returned error: %v", err)
+	}
+	if message := <-messages; message != `deployment "web" successfully rolled out` {
+		t.Errorf("unexpected final message %q", message)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelShort()
+	clientset = fake.NewSimpleClientset(rolling)
+	if err := RolloutStatus(short, clientset, RolloutDeployment, "web", "default", func(string) {}); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("expected ErrWaitTimeout, got %v", err)
+	}
+}
+
+func TestDeploymentHistoryAndUndo(t *testing.T) {
+	other := newReplicaSet(9, "other:1", "")
+	other.OwnerReferences = nil
+	clientset := fake.NewSimpleClientset(
+		newRolloutDeployment("web:3"),
+		newReplicaSet(2, "web:2", "kuba set image web app=web:2"),
+		newReplicaSet(1, "web:1", ""),
+		newReplicaSet(3, "web:3", "kuba set image web app=web:3"),
+		other,
+	)
+
+	history, err := RolloutHistory(clientset, RolloutDeployment, "web", "default")
+	if err != nil {
+		t.Fatalf("RolloutHistory returned error: %v", err)
+	}
+	if len(history) != 3 || history[0].Revision != 1 || history[2].Revision != 3 {
+		t.Fatalf("expected the 3 owned revisions in order, got %+v", history)
+	}
+	if history[1].ChangeCause != "kuba set image web app=web:2" || history[1].Images != "web:2" {
+		t.Errorf("unexpected revision 2: %+v", history[1])
+	}
+
+	revision, err := RolloutUndo(clientset, RolloutDeployment, "web", "default", 0)
+	if err != nil || revision != 2 {
+		t.Fatalf("expected to roll back to revision 2, got %d, %v", revision, err)
+	}
+	deployment, _ := clientset.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != "web:2" {
+		t.Errorf("expected the template of revision 2, got image %s", image)
+	}
+	if _, found := deployment.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; found {
+		t.Error("expected the pod-template-hash label to be dropped")
+	}
+	if deployment.Annotations[ChangeCauseAnnotation] != "kuba set image web app=web:2" {
+		t.Errorf("expected the change-cause of revision 2, got %v", deployment.Annotations)
+	}
+
+	if _, err := RolloutUndo(clientset, RolloutDeployment, "web", "default", 7); err == nil {
+		t.Error("expected an error for a missing revision")
+	}
+}
+
+func TestStatefulSetUndoAndRestart(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: types.UID("db-uid")},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: webLabels},
+			Template: podTemplate("db:2"),
+		},
+	}
+	revision := func(number int64, image string) *appsv1.ControllerRevision {
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "db-" + strconv.FormatInt(number, 10),
+				Namespace:       "default",
+				Labels:          webLabels,
+				OwnerReferences: []metav1.OwnerReference{{UID: types.UID("db-uid")}},
+			},
+			Revision: number,
+			Data:     runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","metadata":{"labels":{"app":"web"}},"spec":{"containers":[{"name":"app","image":"` + image + `"}]}}}}`)},
+		}
+	}
+	clientset := fake.NewSimpleClientset(statefulSet, revision(1, "db:1"), revision(2, "db:2"))
+
+	history, err := RolloutHistory(clientset, RolloutStatefulSet, "db", "default")
+	if err != nil || len(history) != 2 || history[0].Images != "db:1" {
+		t.Fatalf("unexpected history %+v, %v", history, err)
+	}
+
+	if _, err := RolloutUndo(clientset, RolloutStatefulSet, "db", "default", 1); err != nil {
+		t.Fatalf("RolloutUndo returned error: %v", err)
+	}
+	restarted := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	if err := RolloutRestart(clientset, RolloutStatefulSet, "db", "default", restarted); err != nil {
+		t.Fatalf("RolloutRestart returned error: %v", err)
+	}
+
+	updated, _ := clientset.AppsV1().StatefulSets("default").Get(context.TODO(), "db", metav1.GetOptions{})
+	if image := updated.Spec.Template.Spec.Containers[0].Image; image != "db:1" {
+		t.Errorf("expected the template of revision 1, got image %s", image)
+	}
+	if stamp := updated.Spec.Template.Annotations[RestartedAtAnnotation]; stamp != "2024-05-06T07:08:09Z" {
+		t.Errorf("expected the restart annotation, got %q", stamp)
+	}
+}
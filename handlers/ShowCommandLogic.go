@@ -3,19 +3,24 @@ package handlers
 import (
 	"context"
 	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/client-go/kubernetes"
+	"strings"
 	"time"
 )
 
 type ResourceInfo struct {
-	Kind      string
-	Name      string
-	Namespace string
-	CreatedAt time.Time
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
-func ResourceInfos(clientset *kubernetes.Clientset, namespace string) ([]ResourceInfo, error) {
+func ResourceInfos(clientset kubernetes.Interface, namespace string) ([]ResourceInfo, error) {
 	var resources []ResourceInfo
 
 	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
@@ -23,12 +28,7 @@ func ResourceInfos(clientset *kubernetes.Clientset, namespace string) ([]Resourc
 		return nil, err
 	}
 	for _, deployment := range deployments.Items {
-		resources = append(resources, ResourceInfo{
-			Kind:      "Deployment",
-			Name:      deployment.Name,
-			Namespace: deployment.Namespace,
-			CreatedAt: deployment.CreationTimestamp.Time,
-		})
+		resources = append(resources, resourceInfoOf("Deployment", deployment.ObjectMeta))
 	}
 
 	services, err := clientset.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
@@ -36,24 +36,35 @@ func ResourceInfos(clientset *kubernetes.Clientset, namespace string) ([]Resourc
 		return nil, err
 	}
 	for _, service := range services.Items {
-		resources = append(resources, ResourceInfo{
-			Kind:      "Service",
-			Name:      service.Name,
-			Namespace: service.Namespace,
-			CreatedAt: service.CreationTimestamp.Time,
-		})
+		resources = append(resources, resourceInfoOf("Service", service.ObjectMeta))
 	}
 	return resources, nil
 }
 
+func resourceInfoOf(kind string, object metav1.ObjectMeta) ResourceInfo {
+	return ResourceInfo{
+		Kind:      kind,
+		Name:      object.Name,
+		Namespace: object.Namespace,
+		CreatedAt: object.CreationTimestamp.Time,
+	}
+}
+
 type DeploymentInfo struct {
-	Name      string
-	Namespace string
-	Ready     string
-	Age       string
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Ready is the number of ready replicas out of the desired ones, as
+	// "ready/desired".
+	Ready      string `json:"ready"`
+	UpToDate   int32  `json:"upToDate"`
+	Available  int32  `json:"available"`
+	Age        string `json:"age"`
+	Containers string `json:"containers"`
+	Images     string `json:"images"`
+	Selector   string `json:"selector"`
 }
 
-func ShowDeployments(clientset *kubernetes.Clientset, namespace string) ([]DeploymentInfo, error) {
+func ShowDeployments(clientset kubernetes.Interface, namespace string) ([]DeploymentInfo, error) {
 	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return nil, err
@@ -61,32 +72,49 @@ func ShowDeployments(clientset *kubernetes.Clientset, namespace string) ([]Deplo
 
 	var deploymentList []DeploymentInfo
 	for _, deployment := range deployments.Items {
+		deploymentList = append(deploymentList, deploymentInfoOf(deployment))
+	}
+	return deploymentList, nil
+}
 
-		replicaReady := *deployment.Spec.Replicas
-		totalReplica := deployment.Status.ReadyReplicas
-		deploymentCreatorTimeStamp := deployment.CreationTimestamp
-		age := time.Since(deploymentCreatorTimeStamp.Time).Round(time.Second)
-
-		ready := fmt.Sprintf("%v/%v", replicaReady, totalReplica)
+func deploymentInfoOf(deployment appsv1.Deployment) DeploymentInfo {
+	// The API server defaults unset replicas to 1.
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
 
-		deploymentInfo := DeploymentInfo{
-			Name:      deployment.Name,
-			Namespace: string(deployment.Namespace),
-			Ready:     ready,
-			Age:       age.String(),
+	var containers []string
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		containers = append(containers, container.Name)
+	}
+	selector := ""
+	if deployment.Spec.Selector != nil {
+		if labelSelector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector); err == nil {
+			selector = labelSelector.String()
 		}
-		deploymentList = append(deploymentList, deploymentInfo)
 	}
-	return deploymentList, nil
+
+	return DeploymentInfo{
+		Name:       deployment.Name,
+		Namespace:  deployment.Namespace,
+		Ready:      fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, desired),
+		UpToDate:   deployment.Status.UpdatedReplicas,
+		Available:  deployment.Status.AvailableReplicas,
+		Age:        duration.HumanDuration(time.Since(deployment.CreationTimestamp.Time)),
+		Containers: strings.Join(containers, ","),
+		Images:     templateImages(deployment.Spec.Template.Spec.Containers),
+		Selector:   selector,
+	}
 }
 
 type NamespaceInfo struct {
-	Name   string
-	Status string
-	Age    string
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Age    string `json:"age"`
 }
 
-func NameSpaceShower(clientset *kubernetes.Clientset) ([]NamespaceInfo, error) {
+func NameSpaceShower(clientset kubernetes.Interface) ([]NamespaceInfo, error) {
 	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return nil, err
@@ -107,3 +135,149 @@ func NameSpaceShower(clientset *kubernetes.Clientset) ([]NamespaceInfo, error) {
 	}
 	return namespaceInfoList, nil
 }
+
+type PodInfo struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Ready     string `json:"ready"`
+	Status    string `json:"status"`
+	Restarts  int32  `json:"restarts"`
+	Age       string `json:"age"`
+	Node      string `json:"node"`
+	IP        string `json:"ip"`
+}
+
+func ShowPods(clientset kubernetes.Interface, namespace string) ([]PodInfo, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var podList []PodInfo
+	for _, pod := range pods.Items {
+
+		readyContainers := 0
+		var restarts int32
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.Ready {
+				readyContainers++
+			}
+			restarts += containerStatus.RestartCount
+		}
+		podInfo := PodInfo{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Ready:     fmt.Sprintf("%d/%d", readyContainers, len(pod.Spec.Containers)),
+			Status:    podStatus(&pod),
+			Restarts:  restarts,
+			Age:       duration.HumanDuration(time.Since(pod.CreationTimestamp.Time)),
+			Node:      pod.Spec.NodeName,
+			IP:        pod.Status.PodIP,
+		}
+		podList = append(podList, podInfo)
+	}
+	return podList, nil
+}
+
+// podStatus summarises a pod the way kubectl get pods does: the reason a
+// container is waiting or was terminated wins over the pod phase.
+func podStatus(pod *corev1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+
+	status := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		status = pod.Status.Reason
+	}
+
+	for _, containerStatus := range pod.Status.InitContainerStatuses {
+		if containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode == 0 {
+			continue
+		}
+		if containerStatus.State.Waiting != nil && containerStatus.State.Waiting.Reason != "" && containerStatus.State.Waiting.Reason != "PodInitializing" {
+			return "Init:" + containerStatus.State.Waiting.Reason
+		}
+		if containerStatus.State.Terminated != nil {
+			return "Init:Error"
+		}
+		return "Init:" + status
+	}
+
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.State.Waiting != nil && containerStatus.State.Waiting.Reason != "" {
+			status = containerStatus.State.Waiting.Reason
+		} else if containerStatus.State.Terminated != nil && containerStatus.State.Terminated.Reason != "" {
+			status = containerStatus.State.Terminated.Reason
+		}
+	}
+	return status
+}
+
+type ServiceInfo struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Type       string `json:"type"`
+	ClusterIP  string `json:"clusterIP"`
+	ExternalIP string `json:"externalIP"`
+	Ports      string `json:"ports"`
+	Age        string `json:"age"`
+	Selector   string `json:"selector"`
+}
+
+func ShowServices(clientset kubernetes.Interface, namespace string) ([]ServiceInfo, error) {
+	services, err := clientset.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var serviceList []ServiceInfo
+	for _, service := range services.Items {
+
+		var ports []string
+		for _, port := range service.Spec.Ports {
+			if port.NodePort != 0 {
+				ports = append(ports, fmt.Sprintf("%d:%d/%s", port.Port, port.NodePort, port.Protocol))
+			} else {
+				ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+			}
+		}
+		serviceInfo := ServiceInfo{
+			Name:       service.Name,
+			Namespace:  service.Namespace,
+			Type:       string(service.Spec.Type),
+			ClusterIP:  service.Spec.ClusterIP,
+			ExternalIP: serviceExternalIP(&service),
+			Ports:      strings.Join(ports, ","),
+			Age:        duration.HumanDuration(time.Since(service.CreationTimestamp.Time)),
+			Selector:   labels.SelectorFromSet(service.Spec.Selector).String(),
+		}
+		serviceList = append(serviceList, serviceInfo)
+	}
+	return serviceList, nil
+}
+
+func serviceExternalIP(service *corev1.Service) string {
+	externalIPs := append([]string{}, service.Spec.ExternalIPs...)
+
+	switch service.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				externalIPs = append(externalIPs, ingress.IP)
+			} else if ingress.Hostname != "" {
+				externalIPs = append(externalIPs, ingress.Hostname)
+			}
+		}
+		if len(externalIPs) == 0 {
+			return "<pending>"
+		}
+	case corev1.ServiceTypeExternalName:
+		return service.Spec.ExternalName
+	}
+
+	if len(externalIPs) == 0 {
+		return "<none>"
+	}
+	return strings.Join(externalIPs, ",")
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResourceInfos(t *testing.T) {
+	created := metav1.NewTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", CreationTimestamp: created}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", CreationTimestamp: created}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kube-system"}},
+	)
+
+	resources, err := ResourceInfos(clientset, "default")
+	if err != nil {
+		t.Fatalf("ResourceInfos returned error: %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("expected 2 resources, got %d: %+v", len(resources), resources)
+	}
+	if resources[0].Kind != "Deployment" || resources[1].Kind != "Service" {
+		t.Errorf("expected deployments before services, got %+v", resources)
+	}
+	if !resources[0].CreatedAt.Equal(created.Time) {
+		t.Errorf("expected creation time %v, got %v", created.Time, resources[0].CreatedAt)
+	}
+}
+
+func TestResourceInfosAllNamespaces(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "kube-system"}},
+	)
+
+	resources, err := ResourceInfos(clientset, "")
+	if err != nil {
+		t.Fatalf("ResourceInfos returned error: %v", err)
+	}
+	if len(resources) != 2 {
+		t.Errorf("expected resources from every namespace, got %+v", resources)
+	}
+}
+
+func TestShowDeployments(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "web",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-(76*time.Hour + 10*time.Minute))),
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: int32Ptr(3),
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web", "app": "shop"}},
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "app", Image: "web:2"},
+					{Name: "proxy", Image: "envoy:1.30"},
+				}}},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 1, UpdatedReplicas: 2, AvailableReplicas: 1},
+		},
+		// Replicas left unset default to 1.
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"}},
+	)
+
+	deploymentList, err := ShowDeployments(clientset, "default")
+	if err != nil {
+		t.Fatalf("ShowDeployments returned error: %v", err)
+	}
+	if len(deploymentList) != 2 {
+		t.Fatalf("expected 2 deployments, got %d", len(deploymentList))
+	}
+
+	web := deploymentList[0]
+	if web.Name != "web" || web.Namespace != "default" {
+		t.Errorf("unexpected deployment info: %+v", web)
+	}
+	if web.Ready != "1/3" || web.UpToDate != 2 || web.Available != 1 {
+		t.Errorf("expected 1/3 ready, 2 up to date and 1 available, got %+v", web)
+	}
+	if web.Age != "3d4h" {
+		t.Errorf("expected a human readable age of 3d4h, got %q", web.Age)
+	}
+	if web.Containers != "app,proxy" || web.Images != "web:2,envoy:1.30" || web.Selector != "app=shop,tier=web" {
+		t.Errorf("unexpected wide columns: %+v", web)
+	}
+	if worker := deploymentList[1]; worker.Ready != "0/1" || worker.Selector != "" {
+		t.Errorf("expected 0/1 ready for unset replicas, got %+v", worker)
+	}
+}
+
+func TestNameSpaceShower(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "old"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating}},
+	)
+
+	namespaceInfoList, err := NameSpaceShower(clientset)
+	if err != nil {
+		t.Fatalf("NameSpaceShower returned error: %v", err)
+	}
+	if len(namespaceInfoList) != 2 {
+		t.Fatalf("expected 2 namespaces, got %d", len(namespaceInfoList))
+	}
+
+	statuses := map[string]string{}
+	for _, ns := range namespaceInfoList {
+		statuses[ns.Name] = ns.Status
+	}
+	if statuses["default"] != "Active" || statuses["old"] != "Terminating" {
+		t.Errorf("unexpected namespace statuses: %v", statuses)
+	}
+}
+
+func TestShowPods(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-(26*time.Hour + 3*time.Minute)))},
+		Spec: corev1.PodSpec{
+			NodeName:   "minikube",
+			Containers: []corev1.Container{{Name: "web"}, {Name: "sidecar"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			PodIP: "10.0.0.7",
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "web", Ready: true, RestartCount: 2, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				{Name: "sidecar", Ready: false, RestartCount: 1, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			},
+		},
+	})
+
+	podList, err := ShowPods(clientset, "default")
+	if err != nil {
+		t.Fatalf("ShowPods returned error: %v", err)
+	}
+	if len(podList) != 1 {
+		t.Fatalf("expected 1 pod, got %d", len(podList))
+	}
+
+	pod := podList[0]
+	if pod.Ready != "1/2" || pod.Status != "Running" || pod.Restarts != 3 || pod.Node != "minikube" || pod.IP != "10.0.0.7" {
+		t.Errorf("unexpected pod info: %+v", pod)
+	}
+	if pod.Age != "26h" {
+		t.Errorf("expected a human readable age of 26h, got %q", pod.Age)
+	}
+}
+
+func TestPodStatus(t *testing.T) {
+	deleted := metav1.Now()
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want string
+	}{
+		{
+			name: "running",
+			pod:  corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+			want: "Running",
+		},
+		{
+			name: "crash loop",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+				},
+			}},
+			want: "CrashLoopBackOff",
+		},
+		{
+			name: "completed",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}},
+				},
+			}},
+			want: "Completed",
+		},
+		{
+			name: "init container waiting",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+				},
+			}},
+			want: "Init:ImagePullBackOff",
+		},
+		{
+			name: "terminating",
+			pod:  corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+			want: "Terminating",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podStatus(&tt.pod); got != tt.want {
+				t.Errorf("podStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowServices(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-(76*time.Hour + 10*time.Minute)))},
+			Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeNodePort,
+				ClusterIP: "10.96.0.10",
+				Ports: []corev1.ServicePort{
+					{Port: 80, NodePort: 30080, Protocol: corev1.ProtocolTCP},
+					{Port: 53, Protocol: corev1.ProtocolUDP},
+				},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "lb", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer, ClusterIP: "10.96.0.11"},
+		},
+	)
+
+	serviceList, err := ShowServices(clientset, "default")
+	if err != nil {
+		t.Fatalf("ShowServices returned error: %v", err)
+	}
+
+	services := map[string]ServiceInfo{}
+	for _, service := range serviceList {
+		services[service.Name] = service
+	}
+	web := services["web"]
+	if web.Type != "NodePort" || web.ClusterIP != "10.96.0.10" || web.ExternalIP != "<none>" || web.Ports != "80:30080/TCP,53/UDP" {
+		t.Errorf("unexpected service info: %+v", web)
+	}
+	if web.Age != "3d4h" {
+		t.Errorf("expected a human readable age of 3d4h, got %q", web.Age)
+	}
+	if services["lb"].ExternalIP != "<pending>" {
+		t.Errorf("expected a pending load balancer, got %+v", services["lb"])
+	}
+}
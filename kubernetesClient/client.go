@@ -1,29 +1,105 @@
 package kubernetesClient
 
 import (
-	"flag"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
-	"path/filepath"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-func GetClient() (*kubernetes.Clientset, error) {
-	var kubeconfig *string
-	if home := homedir.HomeDir(); home != "" {
-		kubeconfig = flag.String("kubeconfig", filepath.Join(home, ".kube", "config"), "~/.kube/config")
-	} else {
-		kubeconfig = flag.String("kubeconfig", "", "~/.kube/config")
+// ConfigFlags holds the connection settings shared by every command.
+type ConfigFlags struct {
+	KubeConfig     string
+	Context        string
+	Cluster        string
+	User           string
+	RequestTimeout string
+}
+
+// Flags is filled in from the persistent flags of the root command.
+var Flags = &ConfigFlags{}
+
+// AddFlags registers the connection flags on a flag set.
+func (f *ConfigFlags) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.KubeConfig, "kubeconfig", "", "Path to the kubeconfig file to use (defaults to $KUBECONFIG, then ~/.kube/config)")
+	flags.StringVar(&f.Context, "context", "", "The name of the kubeconfig context to use")
+	flags.StringVar(&f.Cluster, "cluster", "", "The name of the kubeconfig cluster to use")
+	flags.StringVar(&f.User, "user", "", "The name of the kubeconfig user to use")
+	flags.StringVar(&f.RequestTimeout, "request-timeout", "0", "How long to wait for a single server request before giving up (eg: 30s, 1m); 0 means no timeout")
+}
+
+// ToClientConfig builds the kubeconfig loader for the flags. It follows
+// the usual loading rules: --kubeconfig wins, otherwise every file listed in
+// $KUBECONFIG is merged, falling back to ~/.kube/config.
+func (f *ConfigFlags) ToClientConfig() clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = f.KubeConfig
+
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: f.Context,
+		Context: clientcmdapi.Context{
+			Cluster:  f.Cluster,
+			AuthInfo: f.User,
+		},
+		Timeout: f.RequestTimeout,
 	}
-	flag.Parse()
 
-	config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+}
+
+// GetConfig returns the REST config for the flags. When no kubeconfig can
+// be found, for example inside a pod, the in-cluster config is used.
+func GetConfig() (*rest.Config, error) {
+	config, err := Flags.ToClientConfig().ClientConfig()
+	if clientcmd.IsEmptyConfig(err) {
+		if inClusterConfig, inClusterErr := rest.InClusterConfig(); inClusterErr == nil {
+			return inClusterConfig, nil
+		}
+	}
+	return config, err
+}
+
+func GetClient() (kubernetes.Interface, error) {
+	config, err := GetConfig()
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
-	return clientset, err
+	return clientset, nil
+}
+
+// GetDynamicClient returns a client that can work with any resource the
+// cluster serves, including custom resources.
+func GetDynamicClient() (dynamic.Interface, error) {
+	config, err := GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
+
+// GetRESTMapper returns a discovery backed mapper that resolves kinds and
+// resource names (including short names such as "deploy" or "pvc") to the
+// API resources served by the cluster.
+func GetRESTMapper() (meta.RESTMapper, error) {
+	config, err := GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	discoveryClient := memory.NewMemCacheClient(clientset.Discovery())
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	return restmapper.NewShortcutExpander(mapper, discoveryClient, nil), nil
 }
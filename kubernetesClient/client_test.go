@@ -0,0 +1,119 @@
+package kubernetesClient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const clustersKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+- name: prod
+  cluster:
+    server: https://prod.example.com
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev-user
+current-context: dev
+`
+
+const usersKubeconfig = `apiVersion: v1
+kind: Config
+users:
+- name: dev-user
+  user:
+    token: dev-token
+- name: prod-user
+  user:
+    token: prod-token
+`
+
+func writeKubeconfig(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestToClientConfigMergesKubeconfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	clusters := writeKubeconfig(t, dir, "clusters", clustersKubeconfig)
+	users := writeKubeconfig(t, dir, "users", usersKubeconfig)
+	t.Setenv("KUBECONFIG", clusters+string(os.PathListSeparator)+users)
+
+	config, err := (&ConfigFlags{}).ToClientConfig().ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig returned error: %v", err)
+	}
+	if config.Host != "https://dev.example.com" || config.BearerToken != "dev-token" {
+		t.Errorf("expected the merged dev context, got host %q token %q", config.Host, config.BearerToken)
+	}
+}
+
+func TestToClientConfigOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeKubeconfig(t, dir, "users", usersKubeconfig)
+	clusters := writeKubeconfig(t, dir, "clusters", clustersKubeconfig)
+	t.Setenv("KUBECONFIG", clusters+string(os.PathListSeparator)+filepath.Join(dir, "users"))
+
+	flags := &ConfigFlags{Cluster: "prod", User: "prod-user", RequestTimeout: "15s"}
+	config, err := flags.ToClientConfig().ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig returned error: %v", err)
+	}
+	if config.Host != "https://prod.example.com" || config.BearerToken != "prod-token" {
+		t.Errorf("expected the prod overrides, got host %q token %q", config.Host, config.BearerToken)
+	}
+	if config.Timeout != 15*time.Second {
+		t.Errorf("expected a 15s timeout, got %v", config.Timeout)
+	}
+}
+
+func TestToClientConfigExplicitKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "missing"))
+	explicit := writeKubeconfig(t, dir, "explicit", clustersKubeconfig+`users:
+- name: dev-user
+  user:
+    token: explicit-token
+`)
+
+	config, err := (&ConfigFlags{KubeConfig: explicit}).ToClientConfig().ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig returned error: %v", err)
+	}
+	if config.BearerToken != "explicit-token" {
+		t.Errorf("expected the explicit kubeconfig to be used, got token %q", config.BearerToken)
+	}
+}
+
+func TestToClientConfigUnknownContext(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("KUBECONFIG", writeKubeconfig(t, dir, "clusters", clustersKubeconfig))
+
+	if _, err := (&ConfigFlags{Context: "staging"}).ToClientConfig().ClientConfig(); err == nil {
+		t.Error("expected an error for a context that does not exist")
+	}
+}
+
+func TestGetClientReturnsErrors(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	if _, err := GetClient(); err == nil {
+		t.Error("expected an error instead of a panic when no configuration exists")
+	}
+	if _, err := GetClient(); err == nil {
+		t.Error("expected GetClient to be callable more than once")
+	}
+}
@@ -0,0 +1,182 @@
+package printers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/olekukonko/tablewriter"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// Formats lists the values accepted by the -o flag.
+var Formats = []string{"table", "wide", "json", "yaml", "name", "go-template=...", "jsonpath=..."}
+
+// templateFormats are the formats that carry their template after "=".
+var templateFormats = []string{"go-template", "jsonpath"}
+
+// Output is the result of a command, renderable in every supported format.
+type Output struct {
+	// Data is serialized for the json and yaml formats. Handler result
+	// structs carry json tags, so field names are stable across releases.
+	Data interface{}
+	// Names holds "kind/name" for every object, printed by the name format.
+	Names []string
+
+	// Header and Rows render the table format. In wide mode WideHeader and
+	// WideRows are appended to them as extra columns.
+	Header     []string
+	Rows       [][]string
+	WideHeader []string
+	WideRows   [][]string
+
+	// Describe prints the human readable form of commands that show a
+	// single object rather than a table.
+	Describe func(w io.Writer)
+
+	// Raw fetches the API objects behind the result. When set, it is used
+	// instead of Data by commands run with --raw.
+	Raw func() (interface{}, error)
+}
+
+// ValidateFormat reports whether format can be passed to Print.
+func ValidateFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	if name, text := splitTemplateFormat(format); name != "" {
+		if text == "" {
+			return fmt.Errorf("output format %s requires a template (eg: -o %s=...)", name, name)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q, expected one of %v", format, Formats)
+}
+
+// Print writes output to w in the given format. An empty format is the
+// same as "table".
+func Print(w io.Writer, format string, output Output) error {
+	if err := ValidateFormat(format); err != nil {
+		return err
+	}
+
+	if name, text := splitTemplateFormat(format); name != "" {
+		return printTemplate(w, name, text, output.Data)
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(emptyIfNil(output.Data), "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+
+	case "yaml":
+		data, err := yaml.Marshal(emptyIfNil(output.Data))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+
+	case "name":
+		for _, name := range output.Names {
+			if _, err := fmt.Fprintln(w, name); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		if output.Describe != nil {
+			output.Describe(w)
+			return nil
+		}
+		printTable(w, output, format == "wide")
+		return nil
+	}
+}
+
+func printTable(w io.Writer, output Output, wide bool) {
+	header := output.Header
+	rows := output.Rows
+	if wide && len(output.WideHeader) > 0 {
+		header = append(append([]string{}, header...), output.WideHeader...)
+		rows = make([][]string, len(output.Rows))
+		for i, row := range output.Rows {
+			rows[i] = append(append([]string{}, row...), output.WideRows[i]...)
+		}
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+// splitTemplateFormat splits "jsonpath={.name}" into its format name and
+// template. The name is empty for formats that take no template.
+func splitTemplateFormat(format string) (string, string) {
+	for _, name := range templateFormats {
+		if strings.HasPrefix(format, name+"=") {
+			return name, strings.TrimPrefix(format, name+"=")
+		}
+	}
+	return "", ""
+}
+
+// printTemplate evaluates a go-template or JSONPath expression. The data is
+// round-tripped through JSON first, so templates use the same field names as
+// the json output (for example {.availableReplicas}).
+func printTemplate(w io.Writer, name string, text string, data interface{}) error {
+	encoded, err := json.Marshal(emptyIfNil(data))
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return err
+	}
+
+	if name == "jsonpath" {
+		if !strings.Contains(text, "{") {
+			text = "{" + text + "}"
+		}
+		parser := jsonpath.New("output")
+		if err := parser.Parse(text); err != nil {
+			return fmt.Errorf("parsing jsonpath %s: %w", text, err)
+		}
+		if err := parser.Execute(w, generic); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w)
+		return err
+	}
+
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing go-template: %w", err)
+	}
+	return tmpl.Execute(w, generic)
+}
+
+// emptyIfNil turns a nil slice into an empty one, so that listing nothing
+// prints [] rather than null.
+func emptyIfNil(data interface{}) interface{} {
+	value := reflect.ValueOf(data)
+	if value.Kind() == reflect.Slice && value.IsNil() {
+		return reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	}
+	return data
+}
@@ -0,0 +1,135 @@
+package printers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type item struct {
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+}
+
+func testOutput(items []item) Output {
+	output := Output{
+		Data:       items,
+		Header:     []string{"Name", "Replicas"},
+		WideHeader: []string{"Extra"},
+	}
+	for _, i := range items {
+		output.Rows = append(output.Rows, []string{i.Name, fmt.Sprint(i.Replicas)})
+		output.WideRows = append(output.WideRows, []string{"wide-" + i.Name})
+		output.Names = append(output.Names, "item/"+i.Name)
+	}
+	return output
+}
+
+func TestPrintFormats(t *testing.T) {
+	output := testOutput([]item{{Name: "web", Replicas: 2}})
+
+	tests := []struct {
+		format   string
+		contains []string
+		excludes []string
+	}{
+		{format: "", contains: []string{"NAME", "web"}, excludes: []string{"EXTRA"}},
+		{format: "table", contains: []string{"NAME", "web"}, excludes: []string{"wide-web"}},
+		{format: "wide", contains: []string{"EXTRA", "wide-web"}},
+		{format: "json", contains: []string{`"name": "web"`, `"replicas": 2`}},
+		{format: "yaml", contains: []string{"- name: web", "replicas: 2"}},
+		{format: "name", contains: []string{"item/web"}, excludes: []string{"NAME"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Print(&out, tt.format, output); err != nil {
+				t.Fatalf("Print returned error: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(out.String(), unwanted) {
+					t.Errorf("expected output not to contain %q, got:\n%s", unwanted, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPrintEmptyList(t *testing.T) {
+	var out bytes.Buffer
+	if err := Print(&out, "json", testOutput(nil)); err != nil {
+		t.Fatalf("Print returned error: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("expected an empty JSON list, got %q", out.String())
+	}
+}
+
+func TestPrintDescribe(t *testing.T) {
+	output := Output{
+		Data:     item{Name: "web"},
+		Describe: func(w io.Writer) { fmt.Fprintln(w, "Name: web") },
+	}
+
+	var out bytes.Buffer
+	if err := Print(&out, "", output); err != nil {
+		t.Fatalf("Print returned error: %v", err)
+	}
+	if out.String() != "Name: web\n" {
+		t.Errorf("expected the description, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := Print(&out, "json", output); err != nil {
+		t.Fatalf("Print returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"name": "web"`) {
+		t.Errorf("expected JSON, got %q", out.String())
+	}
+}
+
+func TestPrintUnsupportedFormat(t *testing.T) {
+	if err := Print(io.Discard, "xml", Output{}); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestPrintTemplates(t *testing.T) {
+	tests := []struct {
+		format string
+		data   interface{}
+		want   string
+	}{
+		{format: "jsonpath={.replicas}", data: item{Name: "web", Replicas: 3}, want: "3\n"},
+		{format: "jsonpath=.name", data: item{Name: "web"}, want: "web\n"},
+		{format: "jsonpath={[*].name}", data: []item{{Name: "web"}, {Name: "api"}}, want: "web api\n"},
+		{format: "go-template={{.name}}:{{.replicas}}", data: item{Name: "web", Replicas: 2}, want: "web:2"},
+		{format: "go-template={{range .}}{{.name}} {{end}}", data: []item{{Name: "web"}, {Name: "api"}}, want: "web api "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Print(&out, tt.format, Output{Data: tt.data}); err != nil {
+				t.Fatalf("Print returned error: %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, out.String())
+			}
+		})
+	}
+}
+
+func TestPrintTemplateErrors(t *testing.T) {
+	for _, format := range []string{"jsonpath=", "go-template=", "jsonpath={.missing", "go-template={{.name"} {
+		if err := Print(io.Discard, format, Output{Data: item{Name: "web"}}); err == nil {
+			t.Errorf("expected an error for %q", format)
+		}
+	}
+}